@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// catalog is an indexed, read-only view over a list of providers.
+type catalog struct {
+	providers []catwalk.Provider
+	byID      map[catwalk.InferenceProvider]int
+	models    map[catwalk.InferenceProvider]map[string]int
+
+	// data is the JSON encoding of providers, served as is when no filter
+	// is given.
+	data []byte
+	// etag is a strong validator derived from the content of data.
+	etag    string
+	modTime time.Time
+
+	// current caches the catalog without the models retired at the time of
+	// the last request, see (*catalog).at.
+	current atomic.Pointer[retiredView]
+}
+
+// retiredView is a catalog without the models retired over [from, until),
+// the period between two retirement dates.
+type retiredView struct {
+	catalog     *catalog
+	from, until time.Time
+}
+
+func newCatalog(providers []catwalk.Provider) (*catalog, error) {
+	data, err := json.Marshal(providers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode catalog: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	c := &catalog{
+		providers: providers,
+		byID:      make(map[catwalk.InferenceProvider]int, len(providers)),
+		models:    make(map[catwalk.InferenceProvider]map[string]int, len(providers)),
+		data:      append(data, '\n'),
+		etag:      `"` + hex.EncodeToString(sum[:16]) + `"`,
+		modTime:   time.Now().UTC().Truncate(time.Second),
+	}
+	for i, p := range providers {
+		c.byID[p.ID] = i
+		models := make(map[string]int, len(p.Models))
+		for j, m := range p.Models {
+			models[m.ID] = j
+		}
+		c.models[p.ID] = models
+	}
+	return c, nil
+}
+
+// at returns the catalog without the models retired at the given time. The
+// result is cached until the next retirement date, so it is only rebuilt
+// when a model actually retires. Retired default models give way to their
+// replacement, or to no default when it is not served either.
+func (c *catalog) at(now time.Time) (*catalog, error) {
+	if v := c.current.Load(); v != nil && !now.Before(v.from) && (v.until.IsZero() || now.Before(v.until)) {
+		return v.catalog, nil
+	}
+
+	var from, until time.Time
+	result := make([]catwalk.Provider, 0, len(c.providers))
+	for _, p := range c.providers {
+		all := p.Models
+		p.Models = slices.DeleteFunc(slices.Clone(all), func(m catwalk.Model) bool {
+			t := m.RetiresAt.Time
+			switch {
+			case m.RetiresAt.IsZero():
+			case m.Retired(now):
+				if t.After(from) {
+					from = t
+				}
+				return true
+			case until.IsZero() || t.Before(until):
+				until = t
+			}
+			return false
+		})
+		p.DefaultLargeModelID = replacement(all, p.Models, p.DefaultLargeModelID)
+		p.DefaultSmallModelID = replacement(all, p.Models, p.DefaultSmallModelID)
+		result = append(result, p)
+	}
+
+	v, err := newCatalog(result)
+	if err != nil {
+		return nil, err
+	}
+	// The view last changed when the latest retired model retired.
+	v.modTime = c.modTime
+	if from := from.UTC().Truncate(time.Second); from.After(v.modTime) {
+		v.modTime = from
+	}
+	c.current.Store(&retiredView{catalog: v, from: from, until: until})
+	return v, nil
+}
+
+// replacement returns the model to use in place of the model with the given
+// ID once only the kept models are served: the model itself when kept, or
+// else the first of its successive replacements that is. It returns an
+// empty string when there is none, rather than a model missing from the
+// catalog.
+func replacement(all, kept []catwalk.Model, id string) string {
+	// Bounded by the number of models, in case of a replacement cycle.
+	for range len(all) + 1 {
+		if id == "" || slices.ContainsFunc(kept, func(m catwalk.Model) bool { return m.ID == id }) {
+			return id
+		}
+		i := slices.IndexFunc(all, func(m catwalk.Model) bool { return m.ID == id })
+		if i < 0 {
+			return ""
+		}
+		id = all[i].ReplacementModelID
+	}
+	return ""
+}
+
+// provider returns the provider with the given ID.
+func (c *catalog) provider(id catwalk.InferenceProvider) (catwalk.Provider, bool) {
+	i, ok := c.byID[id]
+	if !ok {
+		return catwalk.Provider{}, false
+	}
+	return c.providers[i], true
+}
+
+// model returns the model with the given ID from the given provider.
+func (c *catalog) model(providerID catwalk.InferenceProvider, modelID string) (catwalk.Model, bool) {
+	p, ok := c.provider(providerID)
+	if !ok {
+		return catwalk.Model{}, false
+	}
+	j, ok := c.models[providerID][modelID]
+	if !ok {
+		return catwalk.Model{}, false
+	}
+	return p.Models[j], true
+}
+
+// notModified sets the catalog validators on the response and reports
+// whether the request's conditional headers allow answering with 304 Not
+// Modified. Every response derived from the catalog shares the same
+// validators, since the same URL always yields the same body for a given
+// catalog.
+func (c *catalog) notModified(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("ETag", c.etag)
+	w.Header().Set("Last-Modified", c.modTime.Format(http.TimeFormat))
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatch(inm, c.etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !c.modTime.After(t)
+	}
+	return false
+}
+
+// etagMatch reports whether the If-None-Match header value matches etag,
+// using the weak comparison function.
+func etagMatch(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestEtagMatch(t *testing.T) {
+	const etag = `"abc"`
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "same", header: `"abc"`, want: true},
+		{name: "different", header: `"abd"`},
+		{name: "unquoted", header: `abc`},
+		{name: "weak", header: `W/"abc"`, want: true},
+		{name: "weak different", header: `W/"abd"`},
+		{name: "any", header: `*`, want: true},
+		{name: "list", header: `"x", "abc"`, want: true},
+		{name: "list without spaces", header: `"x","abc","y"`, want: true},
+		{name: "list with weak", header: `"x", W/"abc"`, want: true},
+		{name: "list without match", header: `"x", W/"y"`},
+		{name: "list with any", header: `"x", *`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatch(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	c, err := newCatalog(testProviders())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastModified := c.modTime.Format(http.TimeFormat)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   bool
+	}{
+		{name: "unconditional"},
+		{name: "etag", header: http.Header{"If-None-Match": {c.etag}}, want: true},
+		{name: "weak etag", header: http.Header{"If-None-Match": {"W/" + c.etag}}, want: true},
+		{name: "etag list", header: http.Header{"If-None-Match": {`"old", ` + c.etag}}, want: true},
+		{name: "any etag", header: http.Header{"If-None-Match": {"*"}}, want: true},
+		{name: "old etag", header: http.Header{"If-None-Match": {`"old"`}}},
+		{name: "same date", header: http.Header{"If-Modified-Since": {lastModified}}, want: true},
+		{
+			name:   "later date",
+			header: http.Header{"If-Modified-Since": {c.modTime.Add(time.Hour).Format(http.TimeFormat)}},
+			want:   true,
+		},
+		{
+			name:   "earlier date",
+			header: http.Header{"If-Modified-Since": {c.modTime.Add(-time.Second).Format(http.TimeFormat)}},
+		},
+		{name: "invalid date", header: http.Header{"If-Modified-Since": {"yesterday"}}},
+		{
+			// If-None-Match takes precedence over If-Modified-Since.
+			name: "old etag and same date",
+			header: http.Header{
+				"If-None-Match":     {`"old"`},
+				"If-Modified-Since": {lastModified},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/providers", nil)
+			r.Header = tt.header
+			if r.Header == nil {
+				r.Header = http.Header{}
+			}
+			w := httptest.NewRecorder()
+			if got := c.notModified(w, r); got != tt.want {
+				t.Errorf("notModified = %v, want %v", got, tt.want)
+			}
+			if got := w.Header().Get("ETag"); got != c.etag {
+				t.Errorf("ETag = %q, want %q", got, c.etag)
+			}
+			if got := w.Header().Get("Last-Modified"); got != lastModified {
+				t.Errorf("Last-Modified = %q, want %q", got, lastModified)
+			}
+		})
+	}
+}
+
+func TestConditionalRequests(t *testing.T) {
+	h := newTestServer(t, testProviders())
+	rec := serve(h, http.MethodGet, "/providers", "")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag = %q, want 200 with an ETag", rec.Code, etag)
+	}
+
+	for _, target := range []string{
+		"/providers",
+		"/providers?type=openai",
+		"/providers/anthropic",
+		"/providers/anthropic/models",
+		"/providers/openrouter/models/openai/gpt-5",
+	} {
+		t.Run(target, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set("If-None-Match", etag)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusNotModified {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusNotModified)
+			}
+			if rec.Body.Len() != 0 {
+				t.Errorf("304 response has a body: %q", rec.Body)
+			}
+		})
+	}
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCatalogAt(t *testing.T) {
+	providers := []catwalk.Provider{{
+		ID:                  catwalk.InferenceProviderOpenAI,
+		Name:                "OpenAI",
+		DefaultLargeModelID: "a",
+		DefaultSmallModelID: "d",
+		Models: []catwalk.Model{
+			{ID: "a", RetiresAt: catwalk.DateOf(date("2026-01-01")), ReplacementModelID: "b"},
+			{ID: "b", RetiresAt: catwalk.DateOf(date("2026-06-01")), ReplacementModelID: "c"},
+			{ID: "c"},
+			{ID: "d", RetiresAt: catwalk.DateOf(date("2026-03-01"))},
+		},
+	}}
+	c, err := newCatalog(providers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.modTime = date("2025-01-01")
+
+	tests := []struct {
+		now          string
+		models       []string
+		large, small string
+		modTime      string
+	}{
+		{now: "2025-12-31", models: []string{"a", "b", "c", "d"}, large: "a", small: "d", modTime: "2025-01-01"},
+		{now: "2026-01-01", models: []string{"b", "c", "d"}, large: "b", small: "d", modTime: "2026-01-01"},
+		{now: "2026-03-01", models: []string{"b", "c"}, large: "b", modTime: "2026-03-01"},
+		{now: "2026-06-01", models: []string{"c"}, large: "c", modTime: "2026-06-01"},
+		// Going back in time rebuilds the view as well.
+		{now: "2026-02-01", models: []string{"b", "c", "d"}, large: "b", small: "d", modTime: "2026-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.now, func(t *testing.T) {
+			v, err := c.at(date(tt.now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, _ := v.provider(catwalk.InferenceProviderOpenAI)
+			var models []string
+			for _, m := range p.Models {
+				models = append(models, m.ID)
+			}
+			if !slices.Equal(models, tt.models) {
+				t.Errorf("models = %v, want %v", models, tt.models)
+			}
+			if p.DefaultLargeModelID != tt.large || p.DefaultSmallModelID != tt.small {
+				t.Errorf("defaults = %q, %q, want %q, %q", p.DefaultLargeModelID, p.DefaultSmallModelID, tt.large, tt.small)
+			}
+			if !v.modTime.Equal(date(tt.modTime)) {
+				t.Errorf("modTime = %s, want %s", v.modTime, tt.modTime)
+			}
+			for _, id := range tt.models {
+				if _, ok := v.model(catwalk.InferenceProviderOpenAI, id); !ok {
+					t.Errorf("model %q is not indexed", id)
+				}
+			}
+		})
+	}
+
+	// The original catalog is left untouched.
+	if len(c.providers[0].Models) != 4 || c.providers[0].DefaultLargeModelID != "a" {
+		t.Error("the view modified the catalog")
+	}
+}
+
+func TestCatalogAtCache(t *testing.T) {
+	c, err := newCatalog([]catwalk.Provider{{
+		ID:   catwalk.InferenceProviderOpenAI,
+		Name: "OpenAI",
+		Models: []catwalk.Model{
+			{ID: "a", RetiresAt: catwalk.DateOf(date("2026-01-01"))},
+			{ID: "b", RetiresAt: catwalk.DateOf(date("2026-06-01"))},
+			{ID: "c"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(now time.Time) *catalog {
+		t.Helper()
+		v, err := c.at(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	first := at(date("2026-01-01"))
+	if at(date("2026-01-01")) != first || at(date("2026-05-31").Add(24*time.Hour-time.Nanosecond)) != first {
+		t.Error("the view was rebuilt before the next retirement date")
+	}
+	second := at(date("2026-06-01"))
+	if second == first {
+		t.Fatal("the view was not rebuilt on the retirement date")
+	}
+	if at(date("2030-01-01")) != second {
+		t.Error("the view was rebuilt after the last retirement date")
+	}
+	if before := at(date("2025-12-31")); before == second || len(before.providers[0].Models) != 3 {
+		t.Error("the view was not rebuilt before its period")
+	}
+}
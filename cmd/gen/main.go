@@ -0,0 +1,16 @@
+// Package main is a shortcut to the catwalk gen command, e.g. go run
+// ./cmd/gen openrouter.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/charmbracelet/catwalk/internal/gencmd"
+)
+
+func main() {
+	if err := gencmd.Run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// filter narrows down providers and their models based on the query
+// parameters of a request.
+type filter struct {
+	providerType        catwalk.Type
+	canReason           *bool
+	supportsAttachments *bool
+	minContext          int64
+	maxCostIn           *float64
+	maxCostOut          *float64
+	query               string
+}
+
+func parseFilter(values url.Values) (filter, error) {
+	var f filter
+	f.providerType = catwalk.Type(values.Get("type"))
+	f.query = strings.ToLower(strings.TrimSpace(values.Get("q")))
+
+	var err error
+	if f.canReason, err = parseBoolParam(values, "can_reason"); err != nil {
+		return filter{}, err
+	}
+	if f.supportsAttachments, err = parseBoolParam(values, "supports_attachments"); err != nil {
+		return filter{}, err
+	}
+	if f.maxCostIn, err = parseFloatParam(values, "max_cost_in"); err != nil {
+		return filter{}, err
+	}
+	if f.maxCostOut, err = parseFloatParam(values, "max_cost_out"); err != nil {
+		return filter{}, err
+	}
+	if v := values.Get("min_context"); v != "" {
+		f.minContext, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return filter{}, fmt.Errorf("invalid min_context %q: must be an integer", v)
+		}
+	}
+	return f, nil
+}
+
+func parseBoolParam(values url.Values, name string) (*bool, error) {
+	v := values.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: must be a boolean", name, v)
+	}
+	return &b, nil
+}
+
+func parseFloatParam(values url.Values, name string) (*float64, error) {
+	v := values.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: must be a number", name, v)
+	}
+	return &f, nil
+}
+
+// filtersModels reports whether the filter has any model-level criteria.
+func (f filter) filtersModels() bool {
+	return f.canReason != nil ||
+		f.supportsAttachments != nil ||
+		f.minContext > 0 ||
+		f.maxCostIn != nil ||
+		f.maxCostOut != nil ||
+		f.query != ""
+}
+
+// empty reports whether the filter matches everything.
+func (f filter) empty() bool {
+	return f.providerType == "" && !f.filtersModels()
+}
+
+func (f filter) matchProvider(p catwalk.Provider) bool {
+	return f.providerType == "" || p.Type == f.providerType
+}
+
+func (f filter) matchModel(m catwalk.Model) bool {
+	if f.canReason != nil && m.CanReason != *f.canReason {
+		return false
+	}
+	if f.supportsAttachments != nil && m.SupportsImages != *f.supportsAttachments {
+		return false
+	}
+	if m.ContextWindow < f.minContext {
+		return false
+	}
+	if f.maxCostIn != nil && m.CostPer1MIn > *f.maxCostIn {
+		return false
+	}
+	if f.maxCostOut != nil && m.CostPer1MOut > *f.maxCostOut {
+		return false
+	}
+	if f.query != "" &&
+		!strings.Contains(strings.ToLower(m.ID), f.query) &&
+		!strings.Contains(strings.ToLower(m.Name), f.query) {
+		return false
+	}
+	return true
+}
+
+// models returns the models matching the filter.
+func (f filter) models(models []catwalk.Model) []catwalk.Model {
+	if !f.filtersModels() {
+		return models
+	}
+	matched := []catwalk.Model{}
+	for _, m := range models {
+		if f.matchModel(m) {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
+
+// apply returns the providers matching the filter, each holding only its
+// matching models. Providers left without models are dropped.
+func (f filter) apply(providers []catwalk.Provider) []catwalk.Provider {
+	if f.empty() {
+		return providers
+	}
+	matched := []catwalk.Provider{}
+	for _, p := range providers {
+		if !f.matchProvider(p) {
+			continue
+		}
+		if f.filtersModels() {
+			p.Models = f.models(p.Models)
+			if len(p.Models) == 0 {
+				continue
+			}
+		}
+		matched = append(matched, p)
+	}
+	return matched
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "empty"},
+		{name: "all", query: "type=openai&can_reason=true&supports_attachments=0&min_context=1000&max_cost_in=1.5&max_cost_out=10&q=GPT"},
+		{name: "invalid boolean", query: "can_reason=yes", want: `invalid can_reason "yes": must be a boolean`},
+		{name: "invalid attachments", query: "supports_attachments=maybe", want: `invalid supports_attachments "maybe": must be a boolean`},
+		{name: "invalid integer", query: "min_context=1.5", want: `invalid min_context "1.5": must be an integer`},
+		{name: "invalid number", query: "max_cost_in=cheap", want: `invalid max_cost_in "cheap": must be a number`},
+		{name: "empty value", query: "max_cost_out="},
+		{name: "invalid output cost", query: "max_cost_out=1e", want: `invalid max_cost_out "1e": must be a number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = parseFilter(values)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || err.Error() != tt.want):
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterApply(t *testing.T) {
+	tests := []struct {
+		query string
+		// want lists the IDs of the matching models, by provider.
+		want map[catwalk.InferenceProvider][]string
+	}{
+		{
+			query: "",
+			want: map[catwalk.InferenceProvider][]string{
+				"anthropic":  {"claude-opus", "claude-haiku"},
+				"openrouter": {"openai/gpt-5", "openai/gpt-5-mini"},
+			},
+		},
+		{
+			query: "type=anthropic",
+			want:  map[catwalk.InferenceProvider][]string{"anthropic": {"claude-opus", "claude-haiku"}},
+		},
+		{
+			query: "can_reason=true",
+			want: map[catwalk.InferenceProvider][]string{
+				"anthropic":  {"claude-opus"},
+				"openrouter": {"openai/gpt-5"},
+			},
+		},
+		{
+			query: "can_reason=false&type=openai",
+			want:  map[catwalk.InferenceProvider][]string{"openrouter": {"openai/gpt-5-mini"}},
+		},
+		{
+			query: "supports_attachments=true",
+			want:  map[catwalk.InferenceProvider][]string{"anthropic": {"claude-opus"}},
+		},
+		{
+			query: "min_context=200000",
+			want: map[catwalk.InferenceProvider][]string{
+				"anthropic":  {"claude-opus", "claude-haiku"},
+				"openrouter": {"openai/gpt-5"},
+			},
+		},
+		{
+			query: "max_cost_in=1&max_cost_out=5",
+			want: map[catwalk.InferenceProvider][]string{
+				"anthropic":  {"claude-haiku"},
+				"openrouter": {"openai/gpt-5-mini"},
+			},
+		},
+		{
+			// The query matches IDs and names, ignoring case.
+			query: "q=+MINI+",
+			want:  map[catwalk.InferenceProvider][]string{"openrouter": {"openai/gpt-5-mini"}},
+		},
+		{
+			query: "q=claude+haiku",
+			want:  map[catwalk.InferenceProvider][]string{"anthropic": {"claude-haiku"}},
+		},
+		{
+			// Providers left without models are dropped.
+			query: "q=llama",
+			want:  map[catwalk.InferenceProvider][]string{},
+		},
+	}
+	h := newTestServer(t, testProviders())
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, "/providers?"+tt.query, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+			}
+			var providers []catwalk.Provider
+			if err := json.Unmarshal(rec.Body.Bytes(), &providers); err != nil {
+				t.Fatal(err)
+			}
+			if providers == nil {
+				t.Fatal("got null, want a list")
+			}
+			got := map[catwalk.InferenceProvider][]string{}
+			for _, p := range providers {
+				got[p.ID] = []string{}
+				for _, m := range p.Models {
+					got[p.ID] = append(got[p.ID], m.ID)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got providers %v, want %v", got, tt.want)
+			}
+			for id, models := range tt.want {
+				if !slices.Equal(got[id], models) {
+					t.Errorf("provider %s: got models %v, want %v", id, got[id], models)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterModels(t *testing.T) {
+	h := newTestServer(t, testProviders())
+	rec := serve(h, http.MethodGet, "/providers/openrouter/models?q=nothing", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if body := rec.Body.String(); body != "[]\n" {
+		t.Errorf("body = %q, want an empty list", body)
+	}
+}
+
+func TestInvalidQuery(t *testing.T) {
+	h := newTestServer(t, testProviders())
+	for _, target := range []string{
+		"/providers?can_reason=yes",
+		"/providers?min_context=big",
+		"/providers?max_cost_in=free",
+		"/providers/anthropic/models?supports_attachments=2",
+		"/providers/anthropic/models?max_cost_out=-",
+	} {
+		t.Run(target, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, target, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			checkErrorResponse(t, rec, catwalk.ErrorCodeInvalidQuery)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package gencmd implements the gen command, which generates the config of
+// a provider from its upstream API.
+package gencmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/internal/generator"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	// Register the generator sources.
+	_ "github.com/charmbracelet/catwalk/internal/generator/apipie"
+	_ "github.com/charmbracelet/catwalk/internal/generator/huggingface"
+	_ "github.com/charmbracelet/catwalk/internal/generator/openrouter"
+)
+
+// Run runs the gen command with the given arguments, following the command
+// name.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	output := fs.String("o", "", "output `path` of the config, - for stdout (default "+generator.ConfigDir+"/<provider>.json)")
+	dryRun := fs.Bool("dry-run", false, "generate the config without writing it")
+	verbose := fs.Bool("v", false, "log every generated model")
+	diff := fs.Bool("diff", false, "print the changes from the existing config")
+	var maxRemovals generator.RemovalLimit
+	fs.Var(&maxRemovals, "max-removals", "refuse to write the config when more than `N` models, or N% of them, are removed")
+	existingPath := fs.String("existing", "", "existing config `path`, to diff with and to keep models from (default the output path, none with -o -)")
+	overridesPath := fs.String("overrides", "", "overrides `file` of the generated models (default "+generator.OverridesDir+"/<provider>.json, if any)")
+	record := fs.String("record", "", "save the upstream responses to `dir`")
+	replay := fs.String("replay", "", "serve the upstream responses saved to `dir` with -record, never reaching the network")
+	baseURL := fs.String("base-url", "", "base `URL` of the upstream API, e.g. of a local stand-in")
+	concurrency := fs.Int("concurrency", 8, "maximum `number` of concurrent requests")
+	rps := fs.Float64("rps", 10, "maximum `number` of requests per second, 0 for no limit")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: catwalk gen [flags] <provider> [provider flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Generates the config of a provider from its upstream API.\n")
+		fmt.Fprintf(fs.Output(), "Run catwalk gen <provider> -h for the flags of a provider.\n\n")
+		fmt.Fprintf(fs.Output(), "Providers: %s\n\nFlags:\n", strings.Join(sourceNames(), ", "))
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if *record != "" && *replay != "" {
+		return errors.New("-record and -replay are mutually exclusive")
+	}
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return errors.New("expected a provider")
+	}
+	source, ok := generator.Lookup(catwalk.InferenceProvider(fs.Arg(0)))
+	if !ok {
+		return fmt.Errorf("no generator for provider %q, expected one of %s", fs.Arg(0), strings.Join(sourceNames(), ", "))
+	}
+	sourceFlags := flag.NewFlagSet("gen "+fs.Arg(0), flag.ExitOnError)
+	sourceFlags.Usage = func() {
+		fmt.Fprintf(sourceFlags.Output(), "Usage: catwalk gen [flags] %s [provider flags]\n\nProvider flags:\n", source.Provider)
+		sourceFlags.PrintDefaults()
+	}
+	source = source.WithFlags(sourceFlags)
+	_ = sourceFlags.Parse(fs.Args()[1:])
+	if sourceFlags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments after the provider: %s", strings.Join(sourceFlags.Args(), " "))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	path := *output
+	if path == "" {
+		path = source.Output()
+	}
+	existing := *existingPath
+	if existing == "" && path != "-" {
+		existing = path
+	}
+	var old catwalk.Provider
+	if existing != "" {
+		var err error
+		if old, err = readConfig(existing); err != nil {
+			return err
+		}
+	}
+
+	overridesFile := *overridesPath
+	if overridesFile == "" {
+		overridesFile = source.OverridesFile()
+	}
+	overrides, err := generator.LoadOverrides(overridesFile)
+	if errors.Is(err, os.ErrNotExist) && *overridesPath == "" {
+		overrides, err = nil, nil
+	}
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	env := &generator.Env{
+		Fetcher:     generator.NewFetcher(),
+		BaseURL:     *baseURL,
+		Concurrency: *concurrency,
+		Existing:    old,
+		Overrides:   overrides,
+		Verbose:     *verbose,
+	}
+	env.Fetcher.Limiter = generator.NewLimiter(*rps)
+	defer env.Fetcher.Limiter.Stop()
+	switch {
+	case *record != "":
+		env.Fetcher.Client.Transport = &generator.Recorder{Dir: *record}
+	case *replay != "":
+		env.Fetcher.Client.Transport = &generator.Replayer{Dir: *replay}
+		// Replayed responses never change, retrying is pointless.
+		env.Fetcher.MaxAttempts = 1
+	}
+	p, err := source.Run(ctx, env)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if *diff || maxRemovals.Enabled() {
+		d := generator.Compare(old, p)
+		if *diff {
+			out := os.Stdout
+			if path == "-" {
+				out = os.Stderr
+			}
+			if err := d.Write(out); err != nil {
+				return err //nolint:wrapcheck
+			}
+		}
+		if err := maxRemovals.Check(d, len(old.Models)); err != nil {
+			return fmt.Errorf("refusing to write %s: %w", path, err)
+		}
+	}
+
+	switch {
+	case *dryRun:
+		fmt.Printf("Generated %s with %d models, not writing %s\n", p.ID, len(p.Models), path)
+		return nil
+	case path == "-":
+		data, err := generator.Encode(p)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		_, err = fmt.Println(string(data))
+		return err //nolint:wrapcheck
+	}
+	if err := generator.WriteFile(path, p); err != nil {
+		return err //nolint:wrapcheck
+	}
+	fmt.Printf("Generated %s with %d models in %s\n", p.ID, len(p.Models), path)
+	return nil
+}
+
+// readConfig reads an existing provider config, returning an empty provider
+// when there is none.
+func readConfig(path string) (catwalk.Provider, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return catwalk.Provider{}, nil
+	}
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to read existing config: %w", err)
+	}
+	var p catwalk.Provider
+	if err := json.Unmarshal(data, &p); err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to read existing config %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func sourceNames() []string {
+	var names []string
+	for _, s := range generator.Sources() {
+		names = append(names, string(s.Provider))
+	}
+	return names
+}
@@ -1,8 +1,8 @@
-// Package main provides a command-line tool to fetch models from APIpie
-// and generate a configuration file for the provider.
+// Package apipie provides the generator source of the APIpie provider
+// config, built from the APIpie models API.
 //
 // LLM-Enhanced Display Names:
-// This tool uses APIpie.ai's LLM service to generate professional display names
+// This source uses APIpie.ai's LLM service to generate professional display names
 // for models based on their IDs and descriptions. The API key is donated to
 // improve the user experience of this open source project.
 //
@@ -11,23 +11,21 @@
 // display names. This should be set in GitHub Actions secrets.
 //
 // Fallback Behavior:
-// If the APIpie API key is not working or not provided, the tool will fall back
-// to using the raw model ID as the display name. This ensures the tool never
+// If the APIpie API key is not working or not provided, the source will fall back
+// to using the raw model ID as the display name. This ensures the generator never
 // breaks due to API issues.
 //
 // Example usage:
 //
 //	export APIPIE_DISPLAY_NAME_API_KEY="your-apipie-api-key"
-//	go run cmd/apipie/main.go
-package main
+//	go run . gen apipie
+package apipie
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
@@ -36,47 +34,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/catwalk/internal/generator"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 )
 
-// retryableHTTPRequest performs an HTTP request with exponential backoff retry for 502 errors
-func retryableHTTPRequest(req *http.Request, operation string) (*http.Response, error) {
-	maxRetries := 3
-	baseDelay := 1 * time.Second
+const baseURL = "https://apipie.ai/v1"
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		client := &http.Client{Timeout: 30 * time.Second}
+// CachePath is the path of the cache of the LLM-generated display names and
+// reasoning effort analyses, relative to the root of the repository. It can
+// be overridden with the APIPIE_CACHE_PATH environment variable, e.g. to
+// replay recorded responses without touching the shared cache.
+const CachePath = "internal/generator/apipie/cache.db"
 
-		resp, err := client.Do(req)
-		if err != nil {
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("%s failed after %d retries: %w", operation, maxRetries, err)
-			}
-			delay := baseDelay * time.Duration(1<<attempt)
-			log.Printf("%s failed, retrying in %v (attempt %d/%d): %v", operation, delay, attempt+1, maxRetries, err)
-			time.Sleep(delay)
-			continue
-		}
-
-		// Success or non-retryable error
-		if resp.StatusCode == 200 || resp.StatusCode != 502 {
-			return resp, nil
-		}
-
-		// 502 error - retry with backoff
-		if attempt == maxRetries-1 {
-			body, _ := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			return nil, fmt.Errorf("%s returned status %d after %d retries: %s", operation, resp.StatusCode, maxRetries, string(body))
-		}
-
-		resp.Body.Close()
-		delay := baseDelay * time.Duration(1<<attempt)
-		log.Printf("%s returned 502, retrying in %v (attempt %d/%d)", operation, delay, attempt+1, maxRetries)
-		time.Sleep(delay)
-	}
-
-	return nil, fmt.Errorf("%s max retries exceeded", operation)
+func init() {
+	generator.Register(generator.Source{
+		Provider: catwalk.InferenceProviderAPIpie,
+		Generate: generate,
+	})
 }
 
 // Model represents the complete model configuration from APIpie detailed endpoint.
@@ -116,41 +90,25 @@ type ModelsResponse struct {
 	Data   []Model `json:"data"`
 }
 
-func fetchAPIpieModels() (*ModelsResponse, error) {
-	req, _ := http.NewRequestWithContext(
-		context.Background(),
-		"GET",
-		"https://apipie.ai/v1/models/detailed",
-		nil,
-	)
-	req.Header.Set("User-Agent", "Catwalk-Client/1.0")
-
+func fetchModels(ctx context.Context, env *generator.Env) (*ModelsResponse, error) {
+	header := http.Header{"User-Agent": {"Catwalk-Client/1.0"}}
 	// Try to use API key if available
 	if apiKey := os.Getenv("APIPIE_API_KEY"); apiKey != "" {
-		req.Header.Set("x-api-key", apiKey)
-	}
-
-	resp, err := retryableHTTPRequest(req, "Model fetch")
-	if err != nil {
-		return nil, err //nolint:wrapcheck
-	}
-	defer resp.Body.Close() //nolint:errcheck
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, body)
+		header.Set("x-api-key", apiKey)
 	}
 
 	var mr ModelsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&mr); err != nil {
+	if err := env.Fetcher.GetJSON(ctx, env.URL(baseURL, "/models/detailed"), header, &mr); err != nil {
 		return nil, err //nolint:wrapcheck
 	}
 	return &mr, nil
 }
 
 func isTextModel(model Model) bool {
-	// Check if model is enabled, available, and is an LLM
-	return model.Enabled == 1 && model.Available == 1 && model.Type == "llm"
+	// Check if model is enabled, available, and is an LLM with a known
+	// context window
+	return model.Enabled == 1 && model.Available == 1 && model.Type == "llm" &&
+		model.MaxTokens > 0
 }
 
 func supportsImages(model Model) bool {
@@ -162,6 +120,25 @@ func supportsImages(model Model) bool {
 		strings.Contains(strings.ToLower(model.Description), "image")
 }
 
+// toModalities converts APIpie modalities to catwalk ones, skipping the
+// unknown ones. Text is assumed when none are reported.
+func toModalities(values []string) []catwalk.Modality {
+	if len(values) == 0 {
+		return []catwalk.Modality{catwalk.ModalityText}
+	}
+	return generator.ParseModalities(values)
+}
+
+// inputModalities returns the input modalities of the model, including
+// images when they are only advertised in its subtype or description.
+func inputModalities(model Model) []catwalk.Modality {
+	modalities := toModalities(model.InputModalities)
+	if supportsImages(model) && !slices.Contains(modalities, catwalk.ModalityImage) {
+		modalities = append(modalities, catwalk.ModalityImage)
+	}
+	return modalities
+}
+
 func canReason(model Model) bool {
 	// Check if model has reasoning capabilities based on subtype field
 	if model.Subtype != "" {
@@ -171,7 +148,7 @@ func canReason(model Model) bool {
 	return false
 }
 
-func hasReasoningEfforts(cache *Cache, model Model) bool {
+func hasReasoningEfforts(ctx context.Context, cache *Cache, l *llm, model Model) bool {
 	// Only analyze models that can reason (have "reasoning" in subtype)
 	if !canReason(model) {
 		return false
@@ -185,7 +162,7 @@ func hasReasoningEfforts(cache *Cache, model Model) bool {
 		}
 
 		// Cache miss - analyze with LLM
-		result := analyzeReasoningEffortsWithLLM(model.Description)
+		result := analyzeReasoningEffortsWithLLM(ctx, l, model.Description)
 
 		// Cache the result (both positive and negative)
 		if err := cache.SetReasoningEffort(model.Description, result); err != nil {
@@ -222,10 +199,8 @@ func hasReasoningEfforts(cache *Cache, model Model) bool {
 
 // analyzeReasoningEffortsWithLLM uses APIpie.ai to determine if a model
 // supports controllable reasoning efforts based on its description
-func analyzeReasoningEffortsWithLLM(description string) bool {
-	// Use dedicated API key (same as for display name generation, donated for this project)
-	apiKey := os.Getenv("APIPIE_DISPLAY_NAME_API_KEY")
-	if apiKey == "" {
+func analyzeReasoningEffortsWithLLM(ctx context.Context, l *llm, description string) bool {
+	if l.apiKey == "" {
 		return false // Fallback to false if no API key
 	}
 
@@ -244,64 +219,14 @@ Description: "%s"
 
 Answer only "YES" if the model clearly supports controllable reasoning effort, or "NO" if it doesn't or if unclear.`, strings.Split(description, "\n")[0])
 
-	reqBody := APIpieRequest{
-		Messages: []APIpieMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Model:       "claude-sonnet-4-5",
-		MaxTokens:   10,
-		Temperature: 0.1, // Low temperature for consistent results
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		log.Printf("Failed to marshal reasoning effort analysis request: %v", err)
-		return false
-	}
-
-	req, err := http.NewRequestWithContext(
-		context.Background(),
-		"POST",
-		"https://apipie.ai/v1/chat/completions",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		log.Printf("Failed to create reasoning effort analysis request: %v", err)
-		return false
-	}
-
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := retryableHTTPRequest(req, "Reasoning effort analysis")
+	response, err := l.complete(ctx, prompt, 10)
 	if err != nil {
 		log.Printf("Reasoning effort analysis failed: %v", err)
 		return false
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("Reasoning effort analysis returned status %d: %s", resp.StatusCode, string(body))
-		return false
-	}
-
-	var apipieResp APIpieResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apipieResp); err != nil {
-		log.Printf("Failed to decode reasoning effort analysis response: %v", err)
-		return false
-	}
-
-	if len(apipieResp.Choices) == 0 {
-		log.Printf("Reasoning effort analysis returned empty choices")
-		return false
-	}
 
 	// Parse the response
-	response := strings.TrimSpace(strings.ToUpper(apipieResp.Choices[0].Message.Content))
+	response = strings.TrimSpace(strings.ToUpper(response))
 	return strings.Contains(response, "YES")
 }
 
@@ -328,12 +253,56 @@ type APIpieResponse struct {
 	} `json:"choices"`
 }
 
+// errNoAPIKey is returned by llm.complete when no API key is set.
+var errNoAPIKey = errors.New("APIPIE_DISPLAY_NAME_API_KEY is not set")
+
+// llm completes prompts with the APIpie chat completions API, using the
+// API key donated for this project.
+type llm struct {
+	env    *generator.Env
+	apiKey string
+}
+
+func newLLM(env *generator.Env) *llm {
+	return &llm{
+		env:    env,
+		apiKey: os.Getenv("APIPIE_DISPLAY_NAME_API_KEY"),
+	}
+}
+
+// complete returns the completion of the prompt by Claude Sonnet.
+func (l *llm) complete(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	if l.apiKey == "" {
+		return "", errNoAPIKey
+	}
+
+	reqBody := APIpieRequest{
+		Messages: []APIpieMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		Model:       "claude-sonnet-4-5",
+		MaxTokens:   maxTokens,
+		Temperature: 0.1, // Low temperature for consistent results
+	}
+
+	var apipieResp APIpieResponse
+	header := http.Header{"X-Api-Key": {l.apiKey}}
+	if err := l.env.Fetcher.PostJSON(ctx, l.env.URL(baseURL, "/chat/completions"), header, reqBody, &apipieResp); err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	if len(apipieResp.Choices) == 0 {
+		return "", errors.New("empty choices")
+	}
+	return apipieResp.Choices[0].Message.Content, nil
+}
+
 // generateDisplayNamesForGroup uses APIpie.ai to generate professional display names
 // for a group of models with the same ID, helping users differentiate between variants.
-func generateDisplayNamesForGroup(models []Model) map[string]string {
-	// Use dedicated API key for display name generation (donated for this project)
-	apiKey := os.Getenv("APIPIE_DISPLAY_NAME_API_KEY")
-	if apiKey == "" {
+func generateDisplayNamesForGroup(ctx context.Context, l *llm, models []Model) map[string]string {
+	if l.apiKey == "" {
 		return nil
 	}
 
@@ -396,65 +365,14 @@ Generate names in this exact format (one per line):
 [2] -> Display Name Here
 etc.`
 
-	reqBody := APIpieRequest{
-		Messages: []APIpieMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Model:       "claude-sonnet-4-5",
-		MaxTokens:   300,
-		Temperature: 0.1, // Low temperature for consistent results
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		log.Printf("Failed to marshal APIpie request for group display name generation: %v", err)
-		return nil
-	}
-
-	req, err := http.NewRequestWithContext(
-		context.Background(),
-		"POST",
-		"https://apipie.ai/v1/chat/completions",
-		bytes.NewBuffer(jsonData),
-	)
-	if err != nil {
-		log.Printf("Failed to create APIpie request for group display name generation: %v", err)
-		return nil
-	}
-
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := retryableHTTPRequest(req, "Group display name generation")
+	response, err := l.complete(ctx, prompt, 300)
 	if err != nil {
 		log.Printf("APIpie API failed for group display name generation: %v", err)
 		return nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("APIpie API returned status %d for group display name generation: %s", resp.StatusCode, string(body))
-		return nil
-	}
-
-	var apipieResp APIpieResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apipieResp); err != nil {
-		log.Printf("Failed to decode APIpie response for group display name generation: %v", err)
-		return nil
-	}
-
-	if len(apipieResp.Choices) == 0 {
-		log.Printf("APIpie returned empty choices for group display name generation")
-		return nil
-	}
 
 	// Parse the response to extract names
-	response := strings.TrimSpace(apipieResp.Choices[0].Message.Content)
-	return parseGroupNamesResponse(response, models)
+	return parseGroupNamesResponse(strings.TrimSpace(response), models)
 }
 
 // parseGroupNamesResponse parses the LLM response and maps names to models
@@ -496,9 +414,9 @@ func parseIndex(s string) int {
 
 // createDisplayName generates a display name for a model using cache-first approach.
 // This is used for individual models that don't have duplicates.
-func createDisplayName(cache *Cache, model Model) string {
+func createDisplayName(ctx context.Context, cache *Cache, l *llm, model Model) string {
 	// Use the same prompt as group processing (for consistency)
-	result := createDisplayNamesForGroup(cache, []Model{model})
+	result := createDisplayNamesForGroup(ctx, cache, l, []Model{model})
 	key := getModelCacheKey(model)
 	if name, exists := result[key]; exists {
 		return name
@@ -508,7 +426,7 @@ func createDisplayName(cache *Cache, model Model) string {
 }
 
 // createDisplayNamesForGroup generates display names for a group of models with the same ID
-func createDisplayNamesForGroup(cache *Cache, models []Model) map[string]string {
+func createDisplayNamesForGroup(ctx context.Context, cache *Cache, l *llm, models []Model) map[string]string {
 	result := make(map[string]string)
 	uncachedModels := []Model{}
 
@@ -528,7 +446,7 @@ func createDisplayNamesForGroup(cache *Cache, models []Model) map[string]string
 	}
 
 	// Generate names for uncached models as a group
-	if groupNames := generateDisplayNamesForGroup(uncachedModels); groupNames != nil {
+	if groupNames := generateDisplayNamesForGroup(ctx, l, uncachedModels); groupNames != nil {
 		// Cache successful results
 		for key, name := range groupNames {
 			result[key] = name
@@ -587,7 +505,7 @@ func hashModelMetadata(model Model) string {
 
 func getDefaultMaxTokens(model Model) int64 {
 	if model.MaxResponseTokens > 0 {
-		return model.MaxResponseTokens
+		return min(model.MaxResponseTokens, model.MaxTokens)
 	}
 	if model.MaxTokens > 0 {
 		return model.MaxTokens / 4 // Conservative default
@@ -595,14 +513,45 @@ func getDefaultMaxTokens(model Model) int64 {
 	return 4096 // reasonable default
 }
 
-// This is used to generate the apipie.json config file.
-func main() {
+// preferredVariant picks the variant of a model served by the APIpie pool,
+// falling back to the one with the largest context window.
+func preferredVariant(models []Model) Model {
+	best := models[0]
+	for _, model := range models[1:] {
+		switch {
+		case best.Provider == "pool":
+			return best
+		case model.Provider == "pool", model.MaxTokens > best.MaxTokens:
+			best = model
+		}
+	}
+	return best
+}
+
+// parseCost returns a price per million tokens from the confirmed price, which
+// is per million tokens, or the advertised price, which is per token. Missing
+// and negative prices count as zero.
+func parseCost(confirmed, advertisedPerToken string) float64 {
+	if cost, err := strconv.ParseFloat(confirmed, 64); err == nil && cost >= 0 {
+		return cost
+	}
+	if cost, err := strconv.ParseFloat(advertisedPerToken, 64); err == nil && cost >= 0 {
+		return generator.PerMillion(cost)
+	}
+	return 0
+}
+
+func generate(ctx context.Context, env *generator.Env) (catwalk.Provider, error) {
 	// Initialize cache
-	cache, err := NewCache("cmd/apipie/cache.db")
+	cachePath := CachePath
+	if path := os.Getenv("APIPIE_CACHE_PATH"); path != "" {
+		cachePath = path
+	}
+	cache, err := NewCache(cachePath)
 	if err != nil {
-		log.Fatal("Error initializing cache:", err)
+		return catwalk.Provider{}, fmt.Errorf("failed to initialize cache: %w", err)
 	}
-	defer cache.Close()
+	defer cache.Close() //nolint:errcheck
 
 	// Clean old cache entries (older than 30 days)
 	if err := cache.CleanOldEntries(30 * 24 * time.Hour); err != nil {
@@ -614,10 +563,11 @@ func main() {
 		log.Printf("Cache initialized with %d entries", cacheCount)
 	}
 
-	modelsResp, err := fetchAPIpieModels()
+	modelsResp, err := fetchModels(ctx, env)
 	if err != nil {
-		log.Fatal("Error fetching APIpie models:", err)
+		return catwalk.Provider{}, fmt.Errorf("failed to fetch models: %w", err)
 	}
+	l := newLLM(env)
 
 	apipieProvider := catwalk.Provider{
 		Name:                "APIpie",
@@ -638,81 +588,44 @@ func main() {
 		}
 	}
 
-	// Process each group
-	for modelID, models := range modelGroups {
-		var displayNames map[string]string
-
-		if len(models) == 1 {
-			// Single model - use individual processing
-			model := models[0]
-			displayName := createDisplayName(cache, model)
-			key := getModelCacheKey(model)
-			displayNames = map[string]string{key: displayName}
-		} else {
-			// Multiple models with same ID - use group processing
-			log.Printf("Processing %d variants of model %s", len(models), modelID)
-			displayNames = createDisplayNamesForGroup(cache, models)
-		}
-
-		// Create catwalk.Model entries for each model
-		for _, model := range models {
-			key := getModelCacheKey(model)
-			displayName, exists := displayNames[key]
-			if !exists {
-				displayName = model.ID // Fallback
-			}
-
-			// Parse and convert costs to per-million-tokens
-			var costPer1MIn, costPer1MOut float64
-
-			// Confirmed pricing is already per-million-tokens, advertised is per-token
-			if model.Pricing.Confirmed.InputCost != "" {
-				costPer1MIn, _ = strconv.ParseFloat(model.Pricing.Confirmed.InputCost, 64)
-			} else if model.Pricing.Advertised.InputCostPerToken != "" {
-				inputCostPerToken, _ := strconv.ParseFloat(model.Pricing.Advertised.InputCostPerToken, 64)
-				costPer1MIn = inputCostPerToken * 1_000_000
-			}
-
-			if model.Pricing.Confirmed.OutputCost != "" {
-				costPer1MOut, _ = strconv.ParseFloat(model.Pricing.Confirmed.OutputCost, 64)
-			} else if model.Pricing.Advertised.OutputCostPerToken != "" {
-				outputCostPerToken, _ := strconv.ParseFloat(model.Pricing.Advertised.OutputCostPerToken, 64)
-				costPer1MOut = outputCostPerToken * 1_000_000
-			}
-
-			m := catwalk.Model{
-				ID:                 model.ID,
-				Name:               displayName,
-				CostPer1MIn:        costPer1MIn,
-				CostPer1MOut:       costPer1MOut,
-				CostPer1MInCached:  0,
-				CostPer1MOutCached: 0,
-				ContextWindow:      model.MaxTokens,
-				DefaultMaxTokens:   getDefaultMaxTokens(model),
-				CanReason:          canReason(model),
-				HasReasoningEffort: hasReasoningEfforts(cache, model),
-				SupportsImages:     supportsImages(model),
-			}
-
-			apipieProvider.Models = append(apipieProvider.Models, m)
-			fmt.Printf("Added model %s (%s) with context window %d\n", model.ID, displayName, m.ContextWindow)
+	// Process each group. APIpie lists a model once per upstream provider,
+	// but routes requests by ID, so only one variant is kept per ID.
+	for _, models := range modelGroups {
+		model := preferredVariant(models)
+		displayName := createDisplayName(ctx, cache, l, model)
+
+		// Confirmed pricing is already per-million-tokens, advertised is
+		// per-token. Confirmed prices are sometimes negative, in which
+		// case the advertised ones are used.
+		costPer1MIn := parseCost(model.Pricing.Confirmed.InputCost, model.Pricing.Advertised.InputCostPerToken)
+		costPer1MOut := parseCost(model.Pricing.Confirmed.OutputCost, model.Pricing.Advertised.OutputCostPerToken)
+		costPer1MReasoning := parseCost("", model.Pricing.Advertised.InternalReasoning)
+
+		m := catwalk.Model{
+			ID:                 model.ID,
+			Name:               displayName,
+			ContextWindow:      model.MaxTokens,
+			DefaultMaxTokens:   getDefaultMaxTokens(model),
+			CanReason:          canReason(model),
+			HasReasoningEffort: hasReasoningEfforts(ctx, cache, l, model),
+			SupportsImages:     supportsImages(model),
+			// APIpie serves every model through its OpenAI compatible
+			// chat completions API, but does not report tool or
+			// structured output support.
+			SupportsStreaming: true,
+			InputModalities:   inputModalities(model),
+			OutputModalities:  toModalities(model.OutputModalities),
 		}
-	}
-
-	// Sort models by name for consistency
-	slices.SortFunc(apipieProvider.Models, func(a catwalk.Model, b catwalk.Model) int {
-		return strings.Compare(a.Name, b.Name)
-	})
-
-	// Save the JSON in internal/providers/configs/apipie.json
-	data, err := json.MarshalIndent(apipieProvider, "", "  ")
-	if err != nil {
-		log.Fatal("Error marshaling APIpie provider:", err)
-	}
+		m.SetPricing(catwalk.Pricing{
+			TokenPrices: catwalk.TokenPrices{
+				Input:     costPer1MIn,
+				Output:    costPer1MOut,
+				Reasoning: costPer1MReasoning,
+			},
+		})
 
-	// Write to file
-	if err := os.WriteFile("internal/providers/configs/apipie.json", data, 0o600); err != nil {
-		log.Fatal("Error writing APIpie provider config:", err)
+		apipieProvider.Models = append(apipieProvider.Models, m)
+		env.Debugf("Added model %s (%s) with context window %d", model.ID, displayName, m.ContextWindow)
 	}
 
 	// Final cache stats
@@ -720,5 +633,5 @@ func main() {
 		log.Printf("Cache now contains %d entries", finalCount)
 	}
 
-	fmt.Printf("Successfully generated APIpie provider config with %d models\n", len(apipieProvider.Models))
+	return apipieProvider, nil
 }
@@ -1,4 +1,4 @@
-package main
+package apipie
 
 import (
 	"crypto/sha256"
@@ -71,8 +71,6 @@ func (c *Cache) initSchema() error {
 	return err
 }
 
-
-
 // hashDescription creates a SHA256 hash of the model description (legacy function)
 // This allows us to detect when descriptions change and invalidate cache
 func hashDescription(description string) string {
@@ -84,11 +82,11 @@ func hashDescription(description string) string {
 // Returns empty string if not found or metadata has changed
 func (c *Cache) Get(model Model) string {
 	metadataHash := hashModelMetadata(model)
-	
+
 	var displayName string
 	query := `SELECT display_name FROM display_name_cache 
 			  WHERE model_id = ? AND description_hash = ?`
-	
+
 	err := c.db.QueryRow(query, model.ID, metadataHash).Scan(&displayName)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -96,23 +94,23 @@ func (c *Cache) Get(model Model) string {
 		}
 		return ""
 	}
-	
+
 	return displayName
 }
 
 // Set stores a display name in the cache
 func (c *Cache) Set(model Model, displayName string) error {
 	metadataHash := hashModelMetadata(model)
-	
+
 	query := `INSERT OR REPLACE INTO display_name_cache 
 			  (model_id, description_hash, display_name, created_at) 
 			  VALUES (?, ?, ?, ?)`
-	
+
 	_, err := c.db.Exec(query, model.ID, metadataHash, displayName, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to cache display name for model %s: %w", model.ID, err)
 	}
-	
+
 	return nil
 }
 
@@ -127,31 +125,31 @@ func (c *Cache) GetStats() (int, error) {
 // This helps keep the cache size manageable
 func (c *Cache) CleanOldEntries(maxAge time.Duration) error {
 	cutoff := time.Now().Add(-maxAge)
-	
+
 	// Clean display name cache
 	query := `DELETE FROM display_name_cache WHERE created_at < ?`
 	result, err := c.db.Exec(query, cutoff)
 	if err != nil {
 		return fmt.Errorf("failed to clean old display name entries: %w", err)
 	}
-	
+
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected > 0 {
 		log.Printf("Cleaned %d old display name cache entries", rowsAffected)
 	}
-	
+
 	// Clean reasoning effort cache
 	query = `DELETE FROM reasoning_effort_cache WHERE created_at < ?`
 	result, err = c.db.Exec(query, cutoff)
 	if err != nil {
 		return fmt.Errorf("failed to clean old reasoning effort entries: %w", err)
 	}
-	
+
 	rowsAffected, _ = result.RowsAffected()
 	if rowsAffected > 0 {
 		log.Printf("Cleaned %d old reasoning effort cache entries", rowsAffected)
 	}
-	
+
 	return nil
 }
 
@@ -160,19 +158,19 @@ func (c *Cache) GetReasoningEffort(description string) (bool, bool) {
 	if description == "" {
 		return false, false
 	}
-	
+
 	hash := hashDescription(description)
-	
+
 	var hasEffort bool
 	err := c.db.QueryRow(
 		"SELECT has_reasoning_effort FROM reasoning_effort_cache WHERE description_hash = ?",
 		hash,
 	).Scan(&hasEffort)
-	
+
 	if err != nil {
 		return false, false // Cache miss
 	}
-	
+
 	return hasEffort, true // Cache hit
 }
 
@@ -181,17 +179,17 @@ func (c *Cache) SetReasoningEffort(description string, hasEffort bool) error {
 	if description == "" {
 		return nil
 	}
-	
+
 	hash := hashDescription(description)
-	
+
 	_, err := c.db.Exec(
 		"INSERT OR REPLACE INTO reasoning_effort_cache (description_hash, has_reasoning_effort, created_at) VALUES (?, ?, ?)",
 		hash, hasEffort, time.Now(),
 	)
-	
+
 	if err != nil {
 		return fmt.Errorf("failed to cache reasoning effort: %w", err)
 	}
-	
+
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,219 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// Diff is a semantic diff between two versions of a provider config.
+type Diff struct {
+	// Provider is the changes to the provider itself, e.g. to its default
+	// models.
+	Provider []string
+	Added    []catwalk.Model
+	Removed  []catwalk.Model
+	Changed  []ModelChange
+}
+
+// ModelChange lists the changes to a model.
+type ModelChange struct {
+	ID      string
+	Changes []string
+}
+
+// Compare returns the changes from the before to the after version of a
+// provider config.
+func Compare(before, after catwalk.Provider) Diff {
+	var d Diff
+	d.Provider = changes(
+		field("default large model", before.DefaultLargeModelID, after.DefaultLargeModelID),
+		field("default small model", before.DefaultSmallModelID, after.DefaultSmallModelID),
+		field("api endpoint", before.APIEndpoint, after.APIEndpoint),
+	)
+
+	oldModels := make(map[string]catwalk.Model, len(before.Models))
+	for _, m := range before.Models {
+		oldModels[m.ID] = m
+	}
+	newIDs := make(map[string]bool, len(after.Models))
+	for _, m := range after.Models {
+		newIDs[m.ID] = true
+		prev, ok := oldModels[m.ID]
+		if !ok {
+			d.Added = append(d.Added, m)
+			continue
+		}
+		if c := compareModels(prev, m); len(c) > 0 {
+			d.Changed = append(d.Changed, ModelChange{ID: m.ID, Changes: c})
+		}
+	}
+	for _, m := range before.Models {
+		if !newIDs[m.ID] {
+			d.Removed = append(d.Removed, m)
+		}
+	}
+
+	byID := func(a, b catwalk.Model) int { return strings.Compare(a.ID, b.ID) }
+	slices.SortFunc(d.Added, byID)
+	slices.SortFunc(d.Removed, byID)
+	slices.SortFunc(d.Changed, func(a, b ModelChange) int { return cmp.Compare(a.ID, b.ID) })
+	return d
+}
+
+func compareModels(before, after catwalk.Model) []string {
+	oldPrices, newPrices := before.Prices(), after.Prices()
+	return changes(
+		field("name", before.Name, after.Name),
+		number("context window", before.ContextWindow, after.ContextWindow),
+		number("default max tokens", before.DefaultMaxTokens, after.DefaultMaxTokens),
+		price("input price", oldPrices.Input, newPrices.Input),
+		price("output price", oldPrices.Output, newPrices.Output),
+		price("cache read price", oldPrices.CacheRead, newPrices.CacheRead),
+		price("cache write price", oldPrices.CacheWrite, newPrices.CacheWrite),
+		price("reasoning price", oldPrices.Reasoning, newPrices.Reasoning),
+		capability("reasoning", before.CanReason, after.CanReason),
+		capability("images", before.SupportsImages, after.SupportsImages),
+		capability("tools", before.SupportsTools, after.SupportsTools),
+		field("upstream", before.Upstream.String(), after.Upstream.String()),
+	)
+}
+
+// Empty reports whether the diff has no changes.
+func (d Diff) Empty() bool {
+	return len(d.Provider) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Write writes the diff in a human-readable form.
+func (d Diff) Write(w io.Writer) error {
+	var sb strings.Builder
+	if d.Empty() {
+		sb.WriteString("No changes\n")
+	}
+	for _, c := range d.Provider {
+		fmt.Fprintf(&sb, "~ provider: %s\n", c)
+	}
+	for _, m := range d.Added {
+		prices := m.Prices()
+		fmt.Fprintf(&sb, "+ %s (%s): context window %d, $%s/$%s per 1M tokens\n",
+			m.ID, m.Name, m.ContextWindow, formatPrice(prices.Input), formatPrice(prices.Output))
+	}
+	for _, m := range d.Removed {
+		fmt.Fprintf(&sb, "- %s (%s)\n", m.ID, m.Name)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&sb, "~ %s: %s\n", c.ID, strings.Join(c.Changes, ", "))
+	}
+	fmt.Fprintf(&sb, "%d added, %d removed, %d changed\n", len(d.Added), len(d.Removed), len(d.Changed))
+	_, err := io.WriteString(w, sb.String())
+	return err //nolint:wrapcheck
+}
+
+func changes(values ...string) []string {
+	return slices.DeleteFunc(values, func(v string) bool { return v == "" })
+}
+
+func field(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	return fmt.Sprintf("%s %q → %q", name, before, after)
+}
+
+func number(name string, before, after int64) string {
+	if before == after {
+		return ""
+	}
+	return fmt.Sprintf("%s %d → %d%s", name, before, after, percent(float64(before), float64(after)))
+}
+
+func price(name string, before, after float64) string {
+	if before == after {
+		return ""
+	}
+	return fmt.Sprintf("%s $%s → $%s%s", name, formatPrice(before), formatPrice(after), percent(before, after))
+}
+
+func capability(name string, before, after bool) string {
+	switch {
+	case before == after:
+		return ""
+	case after:
+		return "+" + name
+	default:
+		return "-" + name
+	}
+}
+
+func percent(before, after float64) string {
+	if before == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f%%)", (after-before)/before*100)
+}
+
+func formatPrice(p float64) string {
+	// Round away the floating point noise of per token to per million
+	// conversions.
+	return strconv.FormatFloat(math.Round(p*1e6)/1e6, 'f', -1, 64)
+}
+
+// RemovalLimit limits the number of models a generator may remove from a
+// config, as a number of models or, when Percent is set, as a percentage of
+// the models of the config. The zero RemovalLimit sets no limit.
+type RemovalLimit struct {
+	Value   float64
+	Percent bool
+	enabled bool
+}
+
+// Enabled reports whether the limit is set.
+func (l RemovalLimit) Enabled() bool {
+	return l.enabled
+}
+
+// String implements flag.Value.
+func (l *RemovalLimit) String() string {
+	if l == nil || !l.enabled {
+		return ""
+	}
+	s := strconv.FormatFloat(l.Value, 'f', -1, 64)
+	if l.Percent {
+		s += "%"
+	}
+	return s
+}
+
+// Set implements flag.Value, parsing a number of models, e.g. "10", or a
+// percentage of the models, e.g. "20%".
+func (l *RemovalLimit) Set(s string) error {
+	value, percent := strings.CutSuffix(s, "%")
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 || percent && v > 100 {
+		return fmt.Errorf("invalid removal limit %q, expected a number of models or a percentage", s)
+	}
+	*l = RemovalLimit{Value: v, Percent: percent, enabled: true}
+	return nil
+}
+
+// Check returns an error when the diff removes more models than allowed
+// from a config of the given number of models.
+func (l RemovalLimit) Check(d Diff, total int) error {
+	if !l.Enabled() {
+		return nil
+	}
+	limit := l.Value
+	if l.Percent {
+		limit = l.Value / 100 * float64(total)
+	}
+	if removed := len(d.Removed); float64(removed) > limit {
+		return fmt.Errorf("%d of %d models would be removed, more than the limit of %s", removed, total, l.String())
+	}
+	return nil
+}
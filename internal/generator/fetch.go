@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/catwalk/internal/retry"
+)
+
+// Fetcher performs requests to upstream APIs, retrying the ones failing
+// with a network error, a 429 or a 5xx status with exponential backoff.
+type Fetcher struct {
+	Client *http.Client
+	// UserAgent is set on requests that do not have one.
+	UserAgent string
+	// MaxAttempts is the number of attempts of a request, including the
+	// first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled on every
+	// retry unless the response has a Retry-After header.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. A request whose
+	// response asks to retry after a longer delay fails instead, since a
+	// 429 pauses the [Limiter] and thus every other request. Zero sets no
+	// cap.
+	MaxDelay time.Duration
+	// Limiter limits the rate of the requests, retries included. It is nil
+	// by default, setting no limit.
+	Limiter *Limiter
+}
+
+// NewFetcher returns a fetcher with the default settings.
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		UserAgent:   "Crush-Client/1.0",
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+}
+
+// Do sends the request, retrying it when it fails. Requests with a body
+// must have GetBody set, as requests built by [http.NewRequest] with a
+// bytes reader do.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	if f.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+
+	attempts := max(f.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		if err := f.Limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		resp, err := f.Client.Do(req)
+		last := attempt >= attempts
+		if err != nil {
+			if last || req.Context().Err() != nil {
+				return nil, fmt.Errorf("%s %s failed after %d attempts: %w", req.Method, req.URL, attempt, err)
+			}
+			delay := f.delay(attempt)
+			log.Printf("%s %s failed, retrying in %v (attempt %d/%d): %v", req.Method, req.URL, delay, attempt, attempts, err)
+			if err := retry.Sleep(req.Context(), delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if last || !retry.Retryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		delay := f.delay(attempt)
+		if after, ok := retry.After(resp.Header.Get("Retry-After")); ok {
+			delay = after
+		}
+		if f.MaxDelay > 0 && delay > f.MaxDelay {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("%s %s returned %d, asking to retry in %v, more than the maximum of %v", req.Method, req.URL, resp.StatusCode, delay, f.MaxDelay)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			// Hold the other requests too, they would be rate limited
+			// as well.
+			f.Limiter.Pause(delay)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		log.Printf("%s %s returned %d, retrying in %v (attempt %d/%d)", req.Method, req.URL, resp.StatusCode, delay, attempt, attempts)
+		if err := retry.Sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// GetJSON gets the given URL and decodes its JSON response into v.
+func (f *Fetcher) GetJSON(ctx context.Context, url string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return f.doJSON(req, header, v)
+}
+
+// PostJSON posts body, encoded as JSON, to the given URL and decodes its
+// JSON response into v.
+func (f *Fetcher) PostJSON(ctx context.Context, url string, header http.Header, body, v any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return f.doJSON(req, header, v)
+}
+
+func (f *Fetcher) doJSON(req *http.Request, header http.Header, v any) error {
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := f.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned status %d: %s", req.Method, req.URL, resp.StatusCode, body)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", req.Method, req.URL, err)
+	}
+	return nil
+}
+
+func (f *Fetcher) delay(attempt int) time.Duration {
+	d := retry.Backoff(f.BaseDelay, attempt)
+	if f.MaxDelay > 0 {
+		d = min(d, f.MaxDelay)
+	}
+	return d
+}
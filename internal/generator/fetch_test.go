@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetcherMaxDelay(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.Limiter = NewLimiter(0)
+	defer f.Limiter.Stop()
+	var v any
+	err := f.GetJSON(context.Background(), srv.URL, nil, &v)
+	if err == nil || !strings.Contains(err.Error(), "more than the maximum of 1m0s") {
+		t.Fatalf("expected the request to fail past the maximum delay, got: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+	f.Limiter.mu.Lock()
+	defer f.Limiter.mu.Unlock()
+	if !f.Limiter.until.IsZero() {
+		t.Errorf("expected the limiter not to be paused, paused until %v", f.Limiter.until)
+	}
+}
+
+func TestFetcherRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+
+	f := NewFetcher()
+	f.BaseDelay = time.Hour
+	var v struct{ OK bool }
+	if err := f.GetJSON(context.Background(), srv.URL, nil, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.OK || requests.Load() != 2 {
+		t.Errorf("expected a successful retry, got %+v after %d requests", v, requests.Load())
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	for _, rps := range []float64{0, -1, 1e10, 1e-30} {
+		l := NewLimiter(rps)
+		if rps > 0 && rps < 1 && l.ticker == nil {
+			t.Errorf("NewLimiter(%g) sets no limit", rps)
+		}
+		if rps > 1e9 && l.ticker != nil {
+			t.Errorf("NewLimiter(%g) should set no limit", rps)
+		}
+		l.Stop()
+	}
+}
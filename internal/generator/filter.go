@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// Filter reports whether a generated model should be kept.
+type Filter func(catwalk.Model) bool
+
+// Keep returns the models passing all the filters.
+func Keep(models []catwalk.Model, filters ...Filter) []catwalk.Model {
+	return slices.DeleteFunc(slices.Clone(models), func(m catwalk.Model) bool {
+		return slices.ContainsFunc(filters, func(f Filter) bool { return !f(m) })
+	})
+}
+
+// HasContextWindow keeps the models with a known context window.
+func HasContextWindow(m catwalk.Model) bool {
+	return m.ContextWindow > 0
+}
+
+// SupportsTools keeps the models supporting tool calls.
+func SupportsTools(m catwalk.Model) bool {
+	return m.SupportsTools
+}
+
+// Accepts keeps the models accepting the given input modality.
+func Accepts(modality catwalk.Modality) Filter {
+	return func(m catwalk.Model) bool {
+		return m.SupportsInput(modality)
+	}
+}
+
+// Produces keeps the models producing the given output modality.
+func Produces(modality catwalk.Modality) Filter {
+	return func(m catwalk.Model) bool {
+		return m.SupportsOutput(modality)
+	}
+}
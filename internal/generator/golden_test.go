@@ -0,0 +1,132 @@
+package generator_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/internal/generator"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+
+	// Register the generator sources.
+	_ "github.com/charmbracelet/catwalk/internal/generator/apipie"
+	_ "github.com/charmbracelet/catwalk/internal/generator/huggingface"
+	_ "github.com/charmbracelet/catwalk/internal/generator/openrouter"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden configs")
+
+// TestGolden generates every provider from the upstream responses recorded
+// in testdata, served by a local stand-in of the upstream API, and compares
+// the result with the golden config of the provider.
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		provider catwalk.InferenceProvider
+		// basePath is the path of the upstream API the responses were
+		// recorded from.
+		basePath string
+		args     []string
+	}{
+		{provider: catwalk.InferenceProviderOpenRouter, basePath: "/api/v1"},
+		{
+			provider: catwalk.InferenceProviderHuggingFace,
+			basePath: "/v1",
+			args:     []string{"-models", filepath.Join("testdata", "huggingface.models.json")},
+		},
+		{provider: catwalk.InferenceProviderAPIpie, basePath: "/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			// APIpie reads its settings from the environment.
+			t.Setenv("APIPIE_API_KEY", "")
+			t.Setenv("APIPIE_DISPLAY_NAME_API_KEY", "")
+			t.Setenv("APIPIE_CACHE_PATH", filepath.Join(t.TempDir(), "cache.db"))
+
+			source, ok := generator.Lookup(tt.provider)
+			if !ok {
+				t.Fatalf("no source for %s", tt.provider)
+			}
+			fs := flag.NewFlagSet(string(tt.provider), flag.ContinueOnError)
+			source = source.WithFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			overrides, err := generator.LoadOverrides(filepath.Join("testdata", string(tt.provider)+".overrides.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv := httptest.NewServer(replay(t, filepath.Join("testdata", string(tt.provider))))
+			defer srv.Close()
+			env := &generator.Env{
+				Fetcher:     generator.NewFetcher(),
+				BaseURL:     srv.URL + tt.basePath,
+				Concurrency: 4,
+				// Never the live config, which changes every night.
+				Existing:  catwalk.Provider{},
+				Overrides: overrides,
+			}
+			env.Fetcher.MaxAttempts = 1
+
+			p, err := source.Run(context.Background(), env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := generator.Encode(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", string(tt.provider)+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil { //nolint:gosec
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("generated config differs from %s, run go test ./internal/generator -run TestGolden -update and review the changes", golden)
+			}
+		})
+	}
+}
+
+// replay returns a handler serving the responses recorded in dir.
+func replay(t *testing.T, dir string) http.Handler {
+	replayer := &generator.Replayer{Dir: dir}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(body) > 0 {
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+		resp, err := replayer.RoundTrip(r)
+		if err != nil {
+			t.Errorf("unexpected request: %v", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		for name, values := range resp.Header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	})
+}
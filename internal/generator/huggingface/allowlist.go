@@ -0,0 +1,99 @@
+package huggingface
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// AllowListPath is the path of the allow-list of the generated models,
+// relative to the root of the repository.
+const AllowListPath = "internal/generator/huggingface/models.json"
+
+// deniedProviders are the HF Router providers known to be broken, by the
+// reason they are denied. The allow-list cannot list them.
+var deniedProviders = map[string]string{
+	"together": "multiple issues",
+	"novita":   "usage reports are wrong",
+}
+
+// AllowList lists the models of the generated config.
+type AllowList struct {
+	Models []AllowedModel `json:"models"`
+}
+
+// AllowedModel is a model of the allow-list, generated once for each of its
+// providers. HF Router does not report whether models reason or accept
+// attachments, which the allow-list may set instead.
+type AllowedModel struct {
+	// ID is the ID of the model on HF Router, e.g. openai/gpt-oss-20b.
+	ID string `json:"id"`
+	// Providers are the HF Router providers the model is generated for,
+	// e.g. groq.
+	Providers              []string `json:"providers"`
+	CanReason              *bool    `json:"can_reason,omitempty"`
+	HasReasoningEffort     *bool    `json:"has_reasoning_efforts,omitempty"`
+	DefaultReasoningEffort string   `json:"default_reasoning_effort,omitempty"`
+	SupportsImages         *bool    `json:"supports_attachments,omitempty"`
+}
+
+// LoadAllowList loads an allow-list from a JSON file. The allow-list is
+// rejected when it lists a known broken provider.
+func LoadAllowList(path string) (AllowList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AllowList{}, fmt.Errorf("failed to read allow-list: %w", err)
+	}
+	var list AllowList
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&list); err != nil {
+		return AllowList{}, fmt.Errorf("failed to decode allow-list %s: %w", path, err)
+	}
+
+	var errs []error
+	seen := make(map[string]bool, len(list.Models))
+	for _, m := range list.Models {
+		switch {
+		case m.ID == "":
+			errs = append(errs, errors.New("model has no id"))
+		case seen[m.ID]:
+			errs = append(errs, fmt.Errorf("model %q is listed more than once", m.ID))
+		case len(m.Providers) == 0:
+			errs = append(errs, fmt.Errorf("model %q has no providers", m.ID))
+		}
+		seen[m.ID] = true
+		for _, provider := range m.Providers {
+			if reason, ok := deniedProviders[provider]; ok {
+				errs = append(errs, fmt.Errorf("model %q: provider %s is denied, %s", m.ID, provider, reason))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return AllowList{}, fmt.Errorf("invalid allow-list %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// apply sets the capabilities of the allow-list on the model.
+func (a AllowedModel) apply(m *catwalk.Model) {
+	if a.CanReason != nil {
+		m.CanReason = *a.CanReason
+	}
+	if a.HasReasoningEffort != nil {
+		m.HasReasoningEffort = *a.HasReasoningEffort
+	}
+	if a.DefaultReasoningEffort != "" {
+		m.DefaultReasoningEffort = a.DefaultReasoningEffort
+	}
+	if a.SupportsImages != nil {
+		m.SupportsImages = *a.SupportsImages
+		if m.SupportsImages {
+			m.InputModalities = append(m.InputModalities, catwalk.ModalityImage)
+		}
+	}
+}
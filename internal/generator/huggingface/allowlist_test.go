@@ -0,0 +1,72 @@
+package huggingface
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAllowList(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "valid",
+			data: `{"models": [{"id": "openai/gpt-oss-20b", "providers": ["groq", "cerebras"], "can_reason": true}]}`,
+		},
+		{
+			name: "unknown field",
+			data: `{"models": [{"id": "openai/gpt-oss-20b", "providers": ["groq"], "reasoning": true}]}`,
+			want: `unknown field "reasoning"`,
+		},
+		{
+			name: "no id",
+			data: `{"models": [{"providers": ["groq"]}]}`,
+			want: "model has no id",
+		},
+		{
+			name: "duplicate model",
+			data: `{"models": [{"id": "openai/gpt-oss-20b", "providers": ["groq"]}, {"id": "openai/gpt-oss-20b", "providers": ["cerebras"]}]}`,
+			want: `model "openai/gpt-oss-20b" is listed more than once`,
+		},
+		{
+			name: "no providers",
+			data: `{"models": [{"id": "openai/gpt-oss-20b"}]}`,
+			want: `model "openai/gpt-oss-20b" has no providers`,
+		},
+		{
+			name: "denied provider",
+			data: `{"models": [{"id": "moonshotai/Kimi-K2-Instruct-0905", "providers": ["groq", "together"]}]}`,
+			want: `model "moonshotai/Kimi-K2-Instruct-0905": provider together is denied, multiple issues`,
+		},
+		{
+			name: "other denied provider",
+			data: `{"models": [{"id": "moonshotai/Kimi-K2-Instruct-0905", "providers": ["novita"]}]}`,
+			want: "provider novita is denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "models.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadAllowList(path)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAllowListFile(t *testing.T) {
+	if _, err := LoadAllowList("models.json"); err != nil {
+		t.Fatal(err)
+	}
+}
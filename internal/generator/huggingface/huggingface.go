@@ -0,0 +1,226 @@
+// Package huggingface provides the generator source of the Hugging Face
+// provider config, built from the Hugging Face Router models API.
+//
+// Only the models of an allow-list, see [AllowList], are generated, once for
+// each of their providers, with an ID of the form model:provider. The
+// allow-list is rejected if it lists together or novita, which are denied
+// because they are known to be broken.
+//
+// Every generated model is checked against HF Router, which must serve it
+// live with tools from the provider, and validated. Models failing the
+// checks keep their existing entry, if any, so that a flaky upstream never
+// breaks the config.
+package huggingface
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/catwalk/internal/generator"
+	"github.com/charmbracelet/catwalk/internal/providers"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+const baseURL = "https://router.huggingface.co/v1"
+
+func init() {
+	generator.Register(generator.Source{
+		Provider: catwalk.InferenceProviderHuggingFace,
+		Generate: func(ctx context.Context, env *generator.Env) (catwalk.Provider, error) {
+			return generate(ctx, env, AllowListPath)
+		},
+		Filters: []generator.Filter{generator.SupportsTools},
+		Flags: func(fs *flag.FlagSet) generator.GenerateFunc {
+			path := fs.String("models", AllowListPath, "allow-list `file` of the generated models")
+			return func(ctx context.Context, env *generator.Env) (catwalk.Provider, error) {
+				return generate(ctx, env, *path)
+			}
+		},
+	})
+}
+
+// Model represents a model from the Hugging Face Router API.
+type Model struct {
+	ID        string     `json:"id"`
+	Object    string     `json:"object"`
+	Created   int64      `json:"created"`
+	OwnedBy   string     `json:"owned_by"`
+	Providers []Provider `json:"providers"`
+}
+
+// Provider represents a provider configuration for a model.
+type Provider struct {
+	Provider                 string   `json:"provider"`
+	Status                   string   `json:"status"`
+	ContextLength            int64    `json:"context_length,omitempty"`
+	Pricing                  *Pricing `json:"pricing,omitempty"`
+	SupportsTools            bool     `json:"supports_tools"`
+	SupportsStructuredOutput bool     `json:"supports_structured_output"`
+}
+
+// Pricing contains the pricing information for a provider.
+type Pricing struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// ModelsResponse is the response structure for the Hugging Face Router models API.
+type ModelsResponse struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
+
+func fetchModels(ctx context.Context, env *generator.Env) (*ModelsResponse, error) {
+	var mr ModelsResponse
+	if err := env.Fetcher.GetJSON(ctx, env.URL(baseURL, "/models"), nil, &mr); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return &mr, nil
+}
+
+// findContextWindow looks for a context window from any provider for the given model.
+func findContextWindow(model Model) int64 {
+	for _, provider := range model.Providers {
+		if provider.ContextLength > 0 {
+			return provider.ContextLength
+		}
+	}
+	return 0
+}
+
+// newModel builds the model served by the given HF Router provider,
+// returning an error when the provider cannot serve it.
+func newModel(model Model, allowed AllowedModel, providerName string) (catwalk.Model, error) {
+	i := slices.IndexFunc(model.Providers, func(p Provider) bool {
+		return p.Provider == providerName
+	})
+	if i < 0 {
+		return catwalk.Model{}, fmt.Errorf("not served by %s", providerName)
+	}
+	provider := model.Providers[i]
+	switch {
+	case provider.Status != "live":
+		return catwalk.Model{}, fmt.Errorf("%s is %s, not live", providerName, provider.Status)
+	case !provider.SupportsTools:
+		return catwalk.Model{}, fmt.Errorf("%s does not support tools", providerName)
+	}
+
+	// Use provider's context length, or fallback if not available
+	contextLength := provider.ContextLength
+	if contextLength == 0 {
+		contextLength = findContextWindow(model)
+	}
+	if contextLength == 0 {
+		return catwalk.Model{}, errors.New("no context window found in any provider")
+	}
+
+	// Calculate pricing (convert from per-token to per-1M tokens)
+	var costPer1MIn, costPer1MOut float64
+	if provider.Pricing != nil {
+		costPer1MIn = provider.Pricing.Input
+		costPer1MOut = provider.Pricing.Output
+	}
+
+	// Set default max tokens (conservative estimate)
+	defaultMaxTokens := min(contextLength/4, 8192)
+
+	m := catwalk.Model{
+		ID:                fmt.Sprintf("%s:%s", model.ID, providerName),
+		Name:              fmt.Sprintf("%s (%s)", model.ID, providerName),
+		ContextWindow:     contextLength,
+		DefaultMaxTokens:  defaultMaxTokens,
+		SupportsTools:     provider.SupportsTools,
+		SupportsStreaming: true,
+		// HF Router only reports whether the provider supports
+		// response_format, which covers both JSON mode and structured
+		// output.
+		SupportsJSONMode:         provider.SupportsStructuredOutput,
+		SupportsStructuredOutput: provider.SupportsStructuredOutput,
+		InputModalities:          []catwalk.Modality{catwalk.ModalityText},
+		OutputModalities:         []catwalk.Modality{catwalk.ModalityText},
+	}
+	// Cache prices are not provided by HF Router
+	m.SetPricing(catwalk.Pricing{
+		TokenPrices: catwalk.TokenPrices{
+			Input:  costPer1MIn,
+			Output: costPer1MOut,
+		},
+	})
+	if model.Created > 0 {
+		m.ReleasedAt = catwalk.DateOf(time.Unix(model.Created, 0))
+	}
+	// Reasoning and attachments are not provided by HF Router
+	allowed.apply(&m)
+
+	if err := providers.ValidateModel(m); err != nil {
+		return catwalk.Model{}, err //nolint:wrapcheck
+	}
+	return m, nil
+}
+
+// generate generates the models of the allow-list at the given path.
+func generate(ctx context.Context, env *generator.Env, allowListPath string) (catwalk.Provider, error) {
+	allowList, err := LoadAllowList(allowListPath)
+	if err != nil {
+		return catwalk.Provider{}, err
+	}
+	modelsResp, err := fetchModels(ctx, env)
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to fetch models: %w", err)
+	}
+
+	hfProvider := catwalk.Provider{
+		Name:                "Hugging Face",
+		ID:                  catwalk.InferenceProviderHuggingFace,
+		APIKey:              "$HF_TOKEN",
+		APIEndpoint:         "https://router.huggingface.co/v1",
+		Type:                catwalk.TypeOpenAI,
+		DefaultLargeModelID: "moonshotai/Kimi-K2-Instruct-0905:groq",
+		DefaultSmallModelID: "openai/gpt-oss-20b:groq",
+		Models:              []catwalk.Model{},
+		DefaultHeaders: map[string]string{
+			"HTTP-Referer": "https://charm.land",
+			"X-Title":      "Crush",
+		},
+	}
+
+	models := make(map[string]Model, len(modelsResp.Data))
+	for _, model := range modelsResp.Data {
+		models[model.ID] = model
+	}
+	existing := make(map[string]catwalk.Model, len(env.Existing.Models))
+	for _, m := range env.Existing.Models {
+		existing[m.ID] = m
+	}
+
+	for _, allowed := range allowList.Models {
+		for _, providerName := range allowed.Providers {
+			id := fmt.Sprintf("%s:%s", allowed.ID, providerName)
+			var m catwalk.Model
+			err := errors.New("not listed by HF Router")
+			if model, ok := models[allowed.ID]; ok {
+				m, err = newModel(model, allowed, providerName)
+			}
+			if err != nil {
+				if prev, ok := existing[id]; ok {
+					log.Printf("Warning: Keeping the existing %s: %v", id, err)
+					hfProvider.Models = append(hfProvider.Models, prev)
+				} else {
+					log.Printf("Warning: Skipping %s: %v", id, err)
+				}
+				continue
+			}
+
+			hfProvider.Models = append(hfProvider.Models, m)
+			env.Debugf("Added model %s with context window %d from provider %s",
+				m.ID, m.ContextWindow, providerName)
+		}
+	}
+
+	return hfProvider, nil
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/catwalk/internal/retry"
+)
+
+// Limiter limits the rate of the requests of a [Fetcher], shared by all the
+// goroutines using it. A nil Limiter sets no limit.
+type Limiter struct {
+	ticker *time.Ticker
+
+	mu    sync.Mutex
+	until time.Time
+}
+
+// NewLimiter returns a limiter allowing rps requests per second, or any
+// number of them when rps is not positive, or so high that requests would
+// be less than a nanosecond apart. It must be stopped once done.
+func NewLimiter(rps float64) *Limiter {
+	l := &Limiter{}
+	if interval := float64(time.Second) / rps; rps > 0 && interval >= 1 {
+		l.ticker = time.NewTicker(time.Duration(min(interval, math.MaxInt64/2)))
+	}
+	return l
+}
+
+// Wait blocks until the next request is allowed.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	paused := time.Until(l.until)
+	l.mu.Unlock()
+	if paused > 0 {
+		if err := retry.Sleep(ctx, paused); err != nil {
+			return err
+		}
+	}
+	if l.ticker == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+// Pause holds all the requests for d, e.g. when the upstream API asks to
+// retry later.
+func (l *Limiter) Pause(d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+}
+
+// Stop releases the resources of the limiter.
+func (l *Limiter) Stop() {
+	if l != nil && l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
@@ -0,0 +1,28 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// ParseModalities converts upstream modality names to catwalk ones,
+// skipping the unknown ones. "file" is taken to mean PDF documents.
+func ParseModalities(values []string) []catwalk.Modality {
+	modalities := make([]catwalk.Modality, 0, len(values))
+	for _, v := range values {
+		switch strings.ToLower(v) {
+		case "text":
+			modalities = append(modalities, catwalk.ModalityText)
+		case "image":
+			modalities = append(modalities, catwalk.ModalityImage)
+		case "audio":
+			modalities = append(modalities, catwalk.ModalityAudio)
+		case "video":
+			modalities = append(modalities, catwalk.ModalityVideo)
+		case "file", "pdf":
+			modalities = append(modalities, catwalk.ModalityPDF)
+		}
+	}
+	return modalities
+}
@@ -0,0 +1,347 @@
+// Package openrouter provides the generator source of the OpenRouter
+// provider config, built from the OpenRouter models API.
+//
+// OpenRouter serves most models from several upstream providers. Every
+// model is generated from the endpoint of one of them, selected by a
+// [Policy] set from the command line, e.g.:
+//
+//	go run . gen openrouter -prefer tools,price -exclude-quantizations fp8,int4
+//	go run . gen openrouter -policy policy.yaml
+//
+// A policy file sets the fields of [Policy], e.g.:
+//
+//	prefer: [tools, price]
+//	min_uptime: 95
+//	exclude_quantizations: [fp8, int4]
+//
+// With -endpoints variants, every ranked endpoint of a model is also
+// generated as a variant pinned to its upstream provider, with an ID of the
+// form model@tag, e.g. qwen/qwen3-coder@deepinfra/fp8, and the ID to
+// request in its upstream model ID. With -endpoints routes, the ranked
+// endpoints are listed in the routes of the model instead.
+package openrouter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/catwalk/internal/generator"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+const baseURL = "https://openrouter.ai/api/v1"
+
+func init() {
+	generator.Register(generator.Source{
+		Provider: catwalk.InferenceProviderOpenRouter,
+		Generate: defaultConfig().generate,
+		// The endpoint serving a model may not support tools even though
+		// the model does.
+		Filters: []generator.Filter{generator.SupportsTools},
+		Flags:   flags,
+	})
+}
+
+// Model represents the complete model configuration.
+type Model struct {
+	ID              string       `json:"id"`
+	CanonicalSlug   string       `json:"canonical_slug"`
+	HuggingFaceID   string       `json:"hugging_face_id"`
+	Name            string       `json:"name"`
+	Created         int64        `json:"created"`
+	Description     string       `json:"description"`
+	ContextLength   int64        `json:"context_length"`
+	Architecture    Architecture `json:"architecture"`
+	Pricing         Pricing      `json:"pricing"`
+	TopProvider     TopProvider  `json:"top_provider"`
+	SupportedParams []string     `json:"supported_parameters"`
+}
+
+// Architecture defines the model's architecture details.
+type Architecture struct {
+	Modality         string   `json:"modality"`
+	InputModalities  []string `json:"input_modalities"`
+	OutputModalities []string `json:"output_modalities"`
+	Tokenizer        string   `json:"tokenizer"`
+	InstructType     *string  `json:"instruct_type"`
+}
+
+// Pricing contains the pricing information for different operations.
+type Pricing struct {
+	Prompt            string `json:"prompt"`
+	Completion        string `json:"completion"`
+	Request           string `json:"request"`
+	Image             string `json:"image"`
+	WebSearch         string `json:"web_search"`
+	InternalReasoning string `json:"internal_reasoning"`
+	InputCacheRead    string `json:"input_cache_read"`
+	InputCacheWrite   string `json:"input_cache_write"`
+}
+
+// TopProvider describes the top provider's capabilities.
+type TopProvider struct {
+	ContextLength       int64  `json:"context_length"`
+	MaxCompletionTokens *int64 `json:"max_completion_tokens"`
+	IsModerated         bool   `json:"is_moderated"`
+}
+
+// Endpoint represents a single endpoint configuration for a model.
+type Endpoint struct {
+	Name                string   `json:"name"`
+	ContextLength       int64    `json:"context_length"`
+	Pricing             Pricing  `json:"pricing"`
+	ProviderName        string   `json:"provider_name"`
+	Tag                 string   `json:"tag"`
+	Quantization        *string  `json:"quantization"`
+	MaxCompletionTokens *int64   `json:"max_completion_tokens"`
+	MaxPromptTokens     *int64   `json:"max_prompt_tokens"`
+	SupportedParams     []string `json:"supported_parameters"`
+	Status              int      `json:"status"`
+	UptimeLast30m       float64  `json:"uptime_last_30m"`
+}
+
+// EndpointsResponse is the response structure for the endpoints API.
+type EndpointsResponse struct {
+	Data struct {
+		ID          string     `json:"id"`
+		Name        string     `json:"name"`
+		Created     int64      `json:"created"`
+		Description string     `json:"description"`
+		Endpoints   []Endpoint `json:"endpoints"`
+	} `json:"data"`
+}
+
+// ModelsResponse is the response structure for the models API.
+type ModelsResponse struct {
+	Data []Model `json:"data"`
+}
+
+// getPricing converts OpenRouter prices, given per token, request or image,
+// to a catwalk pricing, given per million tokens.
+func getPricing(p Pricing) catwalk.Pricing {
+	return catwalk.Pricing{
+		TokenPrices: catwalk.TokenPrices{
+			Input:      generator.ParsePerToken(p.Prompt),
+			Output:     generator.ParsePerToken(p.Completion),
+			CacheRead:  generator.ParsePerToken(p.InputCacheRead),
+			CacheWrite: generator.ParsePerToken(p.InputCacheWrite),
+			Reasoning:  generator.ParsePerToken(p.InternalReasoning),
+		},
+		PerRequest: generator.ParsePrice(p.Request),
+		PerImage:   generator.ParsePrice(p.Image),
+	}
+}
+
+// setCapabilities fills the capabilities and release date of m from the
+// model and the supported parameters of the model or endpoint it was built
+// from.
+func setCapabilities(m *catwalk.Model, model Model, supportedParams []string) {
+	m.SupportsTools = slices.Contains(supportedParams, "tools")
+	m.SupportsParallelToolCalls = slices.Contains(supportedParams, "parallel_tool_calls")
+	m.SupportsJSONMode = slices.Contains(supportedParams, "response_format")
+	m.SupportsStructuredOutput = slices.Contains(supportedParams, "structured_outputs")
+	// Every model is served through the streaming-capable chat completions
+	// API.
+	m.SupportsStreaming = true
+	m.InputModalities = generator.ParseModalities(model.Architecture.InputModalities)
+	m.OutputModalities = generator.ParseModalities(model.Architecture.OutputModalities)
+	if model.Created > 0 {
+		m.ReleasedAt = catwalk.DateOf(time.Unix(model.Created, 0))
+	}
+}
+
+func fetchModels(ctx context.Context, env *generator.Env) (*ModelsResponse, error) {
+	var mr ModelsResponse
+	if err := env.Fetcher.GetJSON(ctx, env.URL(baseURL, "/models"), nil, &mr); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return &mr, nil
+}
+
+func fetchModelEndpoints(ctx context.Context, env *generator.Env, modelID string) (*EndpointsResponse, error) {
+	var er EndpointsResponse
+	// Model IDs have the form author/slug, both parts being path segments.
+	u := env.URL(baseURL, "/models/"+modelID+"/endpoints")
+	if err := env.Fetcher.GetJSON(ctx, u, nil, &er); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return &er, nil
+}
+
+// endpointModel builds the model served by the given endpoint.
+func endpointModel(model Model, endpoint *Endpoint) catwalk.Model {
+	canReason := slices.Contains(endpoint.SupportedParams, "reasoning")
+	supportsImages := slices.Contains(model.Architecture.InputModalities, "image")
+
+	m := catwalk.Model{
+		ID:                 model.ID,
+		Name:               model.Name,
+		ContextWindow:      endpoint.ContextLength,
+		CanReason:          canReason,
+		HasReasoningEffort: canReason,
+		SupportsImages:     supportsImages,
+	}
+	m.SetPricing(getPricing(endpoint.Pricing))
+
+	// Set max tokens based on the endpoint
+	if endpoint.MaxCompletionTokens != nil {
+		m.DefaultMaxTokens = *endpoint.MaxCompletionTokens / 2
+	} else {
+		m.DefaultMaxTokens = endpoint.ContextLength / 10
+	}
+	setCapabilities(&m, model, endpoint.SupportedParams)
+	route := endpoint.route()
+	m.Upstream = &route
+	return m
+}
+
+// variantName returns the name of the variant of the model pinned to the
+// endpoint, e.g. "Qwen: Qwen3 Coder (DeepInfra, fp8)".
+func variantName(model Model, endpoint *Endpoint) string {
+	if q := endpoint.quantization(); q != "" {
+		return fmt.Sprintf("%s (%s, %s)", model.Name, endpoint.ProviderName, q)
+	}
+	return fmt.Sprintf("%s (%s)", model.Name, endpoint.ProviderName)
+}
+
+// route returns the upstream provider of the endpoint.
+func (e *Endpoint) route() catwalk.Route {
+	return catwalk.Route{
+		Provider:     e.ProviderName,
+		Tag:          e.Tag,
+		Quantization: e.quantization(),
+	}
+}
+
+// detailedRoute returns the upstream provider of the endpoint along with
+// its limits and prices.
+func (e *Endpoint) detailedRoute() catwalk.Route {
+	r := e.route()
+	r.ContextWindow = e.ContextLength
+	r.MaxTokens = e.maxTokens()
+	pricing := getPricing(e.Pricing)
+	r.Pricing = &pricing
+	return r
+}
+
+// generate generates the provider, selecting the endpoints of the models
+// according to the settings.
+func (c config) generate(ctx context.Context, env *generator.Env) (catwalk.Provider, error) {
+	modelsResp, err := fetchModels(ctx, env)
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to fetch models: %w", err)
+	}
+
+	openRouterProvider := catwalk.Provider{
+		Name:                "OpenRouter",
+		ID:                  "openrouter",
+		APIKey:              "$OPENROUTER_API_KEY",
+		APIEndpoint:         "https://openrouter.ai/api/v1",
+		Type:                catwalk.TypeOpenAI,
+		DefaultLargeModelID: "anthropic/claude-sonnet-4",
+		DefaultSmallModelID: "anthropic/claude-haiku-4.5",
+		Models:              []catwalk.Model{},
+		DefaultHeaders: map[string]string{
+			"HTTP-Referer": "https://charm.land",
+			"X-Title":      "Crush",
+		},
+	}
+
+	// skip non‐text models or those without tools
+	models := slices.DeleteFunc(modelsResp.Data, func(model Model) bool {
+		return !slices.Contains(model.SupportedParams, "tools") ||
+			!slices.Contains(model.Architecture.InputModalities, "text") ||
+			!slices.Contains(model.Architecture.OutputModalities, "text")
+	})
+
+	// Fetch the endpoints of every model to get the best configuration
+	endpoints, errs := generator.Parallel(ctx, env.Concurrency, models,
+		func(ctx context.Context, model Model) (*EndpointsResponse, error) {
+			return fetchModelEndpoints(ctx, env, model.ID)
+		})
+	if err := ctx.Err(); err != nil {
+		return catwalk.Provider{}, err //nolint:wrapcheck
+	}
+
+	for i, model := range models {
+		endpointsResp, err := endpoints[i], errs[i]
+		if err != nil {
+			if c.policy.restricts() {
+				// The upstream of the model data is unknown, it may
+				// be ruled out by the policy.
+				log.Printf("Warning: Skipping %s, failed to fetch its endpoints: %v", model.ID, err)
+				continue
+			}
+			log.Printf("Warning: Failed to fetch endpoints for %s: %v", model.ID, err)
+			// Fall back to using the original model data
+			canReason := slices.Contains(model.SupportedParams, "reasoning")
+			supportsImages := slices.Contains(model.Architecture.InputModalities, "image")
+
+			m := catwalk.Model{
+				ID:                 model.ID,
+				Name:               model.Name,
+				ContextWindow:      model.ContextLength,
+				CanReason:          canReason,
+				HasReasoningEffort: canReason,
+				SupportsImages:     supportsImages,
+			}
+			m.SetPricing(getPricing(model.Pricing))
+			if model.TopProvider.MaxCompletionTokens != nil {
+				m.DefaultMaxTokens = *model.TopProvider.MaxCompletionTokens / 2
+			} else {
+				m.DefaultMaxTokens = model.ContextLength / 10
+			}
+			if model.TopProvider.ContextLength > 0 {
+				m.ContextWindow = model.TopProvider.ContextLength
+			}
+			setCapabilities(&m, model, model.SupportedParams)
+			openRouterProvider.Models = append(openRouterProvider.Models, m)
+			continue
+		}
+
+		// Select the best endpoint
+		bestEndpoint := c.policy.selectEndpoint(endpointsResp.Data.Endpoints)
+		if bestEndpoint == nil {
+			log.Printf("Warning: No suitable endpoint found for %s", model.ID)
+			continue
+		}
+
+		m := endpointModel(model, bestEndpoint)
+		ranked := c.policy.rankEndpoints(endpointsResp.Data.Endpoints)
+		if c.mode == modeRoutes {
+			for _, endpoint := range ranked {
+				// Pinning an endpoint without tools would break the
+				// tool calls of the model.
+				if m.SupportsTools && !endpoint.supportsTools() {
+					continue
+				}
+				m.Routes = append(m.Routes, endpoint.detailedRoute())
+			}
+		}
+
+		openRouterProvider.Models = append(openRouterProvider.Models, m)
+		env.Debugf("Added model %s with context window %d from provider %s",
+			model.ID, bestEndpoint.ContextLength, m.Upstream)
+
+		if c.mode != modeVariants {
+			continue
+		}
+		for _, endpoint := range ranked {
+			// Endpoints are pinned by their tag.
+			if endpoint.Tag == "" {
+				continue
+			}
+			v := endpointModel(model, endpoint)
+			v.ID = model.ID + "@" + endpoint.Tag
+			v.Name = variantName(model, endpoint)
+			v.Upstream.ModelID = model.ID
+			openRouterProvider.Models = append(openRouterProvider.Models, v)
+			env.Debugf("Added variant %s of model %s", v.ID, model.ID)
+		}
+	}
+
+	return openRouterProvider, nil
+}
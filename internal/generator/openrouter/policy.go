@@ -0,0 +1,364 @@
+package openrouter
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/internal/generator"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"gopkg.in/yaml.v3"
+)
+
+// Criterion is a criterion ranking the endpoints of a model.
+type Criterion string
+
+// All the criteria ranking the endpoints of a model.
+const (
+	// PreferTools prefers the endpoints supporting tools.
+	PreferTools Criterion = "tools"
+	// PreferContext prefers the endpoints with the largest context window.
+	PreferContext Criterion = "context"
+	// PreferUptime prefers the endpoints with the highest uptime.
+	PreferUptime Criterion = "uptime"
+	// PreferPrice prefers the endpoints with the lowest input and output
+	// prices.
+	PreferPrice Criterion = "price"
+	// PreferMaxTokens prefers the endpoints with the largest max completion
+	// tokens.
+	PreferMaxTokens Criterion = "max_tokens"
+)
+
+var criteria = []Criterion{PreferTools, PreferContext, PreferUptime, PreferPrice, PreferMaxTokens}
+
+// Policy selects the endpoint a model is generated from.
+type Policy struct {
+	// Prefer ranks the endpoints by the first criterion telling them apart.
+	// Ties go to the endpoint listed first by OpenRouter.
+	Prefer []Criterion `json:"prefer" yaml:"prefer"`
+	// MinUptime is the uptime over the last 30 minutes, in percent, below
+	// which an endpoint is only selected when no other is available.
+	MinUptime float64 `json:"min_uptime" yaml:"min_uptime"`
+	// Providers restricts the endpoints to the ones of these upstream
+	// providers, matched by name or tag, when set.
+	Providers []string `json:"providers,omitempty" yaml:"providers,omitempty"`
+	// ExcludeProviders skips the endpoints of these upstream providers.
+	ExcludeProviders []string `json:"exclude_providers,omitempty" yaml:"exclude_providers,omitempty"`
+	// ExcludeQuantizations skips the endpoints serving models with these
+	// quantizations, e.g. fp8 or int4.
+	ExcludeQuantizations []string `json:"exclude_quantizations,omitempty" yaml:"exclude_quantizations,omitempty"`
+}
+
+// DefaultPolicy returns the default policy, preferring the endpoints
+// supporting tools, then the ones with the largest context window, then the
+// most available ones.
+func DefaultPolicy() Policy {
+	return Policy{
+		Prefer:    []Criterion{PreferTools, PreferContext, PreferUptime},
+		MinUptime: 90,
+	}
+}
+
+// LoadPolicy loads a policy from a YAML file, or from a JSON file when its
+// name ends in .json. Omitted fields keep their default value.
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to read policy: %w", err)
+	}
+	p := DefaultPolicy()
+	if err := decodePolicy(path, data, &p); err != nil {
+		return Policy{}, fmt.Errorf("failed to decode policy %s: %w", path, err)
+	}
+	if err := p.Validate(); err != nil {
+		return Policy{}, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// decodePolicy decodes the policy file at path into p, rejecting unknown
+// fields.
+func decodePolicy(path string, data []byte, p *Policy) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(p) //nolint:wrapcheck
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	// An empty file keeps the defaults.
+	if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return err //nolint:wrapcheck
+	}
+	return nil
+}
+
+// Validate checks that the policy only uses known criteria and a valid
+// uptime.
+func (p Policy) Validate() error {
+	for _, c := range p.Prefer {
+		if !slices.Contains(criteria, c) {
+			return fmt.Errorf("unknown criterion %q, expected one of %s", c, joinCriteria(criteria))
+		}
+	}
+	if p.MinUptime < 0 || p.MinUptime > 100 {
+		return fmt.Errorf("min uptime must be a percentage, got %v", p.MinUptime)
+	}
+	return nil
+}
+
+// restricts reports whether the policy rules out some endpoints whatever
+// their ranking.
+func (p Policy) restricts() bool {
+	return len(p.Providers) > 0 || len(p.ExcludeProviders) > 0 || len(p.ExcludeQuantizations) > 0
+}
+
+// allows reports whether the endpoint may be selected.
+func (p Policy) allows(e *Endpoint) bool {
+	if len(p.Providers) > 0 && !slices.ContainsFunc(p.Providers, e.servedBy) {
+		return false
+	}
+	if slices.ContainsFunc(p.ExcludeProviders, e.servedBy) {
+		return false
+	}
+	return !slices.ContainsFunc(p.ExcludeQuantizations, func(q string) bool {
+		return strings.EqualFold(q, e.quantization())
+	})
+}
+
+// rankEndpoints returns the endpoints allowed by the policy and available
+// enough, best first.
+func (p Policy) rankEndpoints(endpoints []Endpoint) []*Endpoint {
+	var ranked []*Endpoint
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		// Skip endpoints with poor status or uptime
+		if !p.allows(endpoint) || endpoint.Status < 0 || endpoint.UptimeLast30m < p.MinUptime {
+			continue
+		}
+		ranked = append(ranked, endpoint)
+	}
+	slices.SortStableFunc(ranked, func(a, b *Endpoint) int {
+		return p.compare(b, a)
+	})
+	return ranked
+}
+
+// selectEndpoint returns the best endpoint allowed by the policy, falling
+// back to the first allowed one when none is available enough, or nil when
+// there is none.
+func (p Policy) selectEndpoint(endpoints []Endpoint) *Endpoint {
+	if ranked := p.rankEndpoints(endpoints); len(ranked) > 0 {
+		return ranked[0]
+	}
+	for i := range endpoints {
+		if p.allows(&endpoints[i]) {
+			return &endpoints[i]
+		}
+	}
+	return nil
+}
+
+// compare returns a positive number when a is better than b, a negative
+// one when it is worse and zero when the policy cannot tell them apart.
+func (p Policy) compare(a, b *Endpoint) int {
+	for _, c := range p.Prefer {
+		var r int
+		switch c {
+		case PreferTools:
+			r = compareBool(a.supportsTools(), b.supportsTools())
+		case PreferContext:
+			r = cmp.Compare(a.ContextLength, b.ContextLength)
+		case PreferUptime:
+			r = cmp.Compare(a.UptimeLast30m, b.UptimeLast30m)
+		case PreferPrice:
+			r = cmp.Compare(b.price(), a.price())
+		case PreferMaxTokens:
+			r = cmp.Compare(a.maxTokens(), b.maxTokens())
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	return 0
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func (e *Endpoint) supportsTools() bool {
+	return slices.Contains(e.SupportedParams, "tools")
+}
+
+func (e *Endpoint) price() float64 {
+	return generator.ParsePerToken(e.Pricing.Prompt) + generator.ParsePerToken(e.Pricing.Completion)
+}
+
+func (e *Endpoint) maxTokens() int64 {
+	if e.MaxCompletionTokens == nil {
+		return 0
+	}
+	return *e.MaxCompletionTokens
+}
+
+func (e *Endpoint) quantization() string {
+	if e.Quantization == nil {
+		return ""
+	}
+	return *e.Quantization
+}
+
+// servedBy reports whether the endpoint is served by the given upstream
+// provider, matching its name, e.g. DeepInfra, its tag, e.g.
+// deepinfra/fp8, or the provider part of its tag, e.g. deepinfra.
+func (e *Endpoint) servedBy(provider string) bool {
+	slug, _, _ := strings.Cut(e.Tag, "/")
+	return strings.EqualFold(provider, e.ProviderName) ||
+		strings.EqualFold(provider, e.Tag) ||
+		strings.EqualFold(provider, slug)
+}
+
+// endpointMode tells which endpoints of a model are generated.
+type endpointMode string
+
+const (
+	// modeBest generates every model from its best endpoint.
+	modeBest endpointMode = "best"
+	// modeVariants also generates a variant of every model for each of its
+	// ranked endpoints, pinned to the upstream provider of the endpoint.
+	modeVariants endpointMode = "variants"
+	// modeRoutes generates every model from its best endpoint, listing
+	// its ranked endpoints as routes.
+	modeRoutes endpointMode = "routes"
+)
+
+// config holds the settings of the generator.
+type config struct {
+	policy Policy
+	mode   endpointMode
+}
+
+// defaultConfig returns the settings used without flags.
+func defaultConfig() config {
+	return config{policy: DefaultPolicy(), mode: modeBest}
+}
+
+// flags registers the flags setting the policy, and returns the function
+// generating the provider with it.
+func flags(fs *flag.FlagSet) generator.GenerateFunc {
+	settings := configFlags(fs)
+	return func(ctx context.Context, env *generator.Env) (catwalk.Provider, error) {
+		return settings().generate(ctx, env)
+	}
+}
+
+// configFlags registers the flags of the settings, and returns the function
+// returning the settings once the flags are parsed. The policy is loaded
+// from the -policy file, if any, then changed by the other flags whatever
+// their order.
+func configFlags(fs *flag.FlagSet) func() config {
+	defaults := defaultConfig()
+	policy, mode := defaults.policy, defaults.mode
+	// changes are applied in order to the policy once every flag is
+	// parsed.
+	var changes []func(p *Policy)
+	change := func(f func(p *Policy)) {
+		changes = append(changes, f)
+	}
+
+	fs.Func("policy", "load the endpoint selection policy from a YAML `file`, or a JSON one ending in .json", func(path string) error {
+		p, err := LoadPolicy(path)
+		if err != nil {
+			return err
+		}
+		policy = p
+		return nil
+	})
+
+	fs.Func("endpoints", "generate the `mode` endpoints of every model: best, variants to also add a model pinned to each endpoint, or routes to list the endpoints of every model (default best)", func(s string) error {
+		switch m := endpointMode(s); m {
+		case modeBest, modeVariants, modeRoutes:
+			mode = m
+			return nil
+		}
+		return fmt.Errorf("unknown mode %q, expected best, variants or routes", s)
+	})
+	fs.Func("prefer", "comma-separated `criteria` ranking the endpoints, among "+joinCriteria(criteria)+
+		" (default "+joinCriteria(defaults.policy.Prefer)+")", func(s string) error {
+		var prefer []Criterion
+		for _, c := range splitList(s) {
+			prefer = append(prefer, Criterion(c))
+		}
+		if err := (Policy{Prefer: prefer}).Validate(); err != nil {
+			return err
+		}
+		change(func(p *Policy) { p.Prefer = prefer })
+		return nil
+	})
+	fs.Func("min-uptime", fmt.Sprintf("minimum uptime of the selected endpoints, in `percent` (default %v)", defaults.policy.MinUptime), func(s string) error {
+		var uptime float64
+		if _, err := fmt.Sscan(s, &uptime); err != nil {
+			return fmt.Errorf("invalid uptime %q", s)
+		}
+		if err := (Policy{MinUptime: uptime}).Validate(); err != nil {
+			return err
+		}
+		change(func(p *Policy) { p.MinUptime = uptime })
+		return nil
+	})
+	fs.Func("providers", "comma-separated upstream `providers` to select the endpoints from", func(s string) error {
+		change(func(p *Policy) { p.Providers = splitList(s) })
+		return nil
+	})
+	fs.Func("exclude-providers", "comma-separated upstream `providers` to skip", func(s string) error {
+		change(func(p *Policy) { p.ExcludeProviders = splitList(s) })
+		return nil
+	})
+	fs.Func("exclude-quantizations", "comma-separated `quantizations` to skip, e.g. fp8,int4", func(s string) error {
+		change(func(p *Policy) { p.ExcludeQuantizations = splitList(s) })
+		return nil
+	})
+
+	return func() config {
+		c := config{policy: policy, mode: mode}
+		for _, f := range changes {
+			f(&c.policy)
+		}
+		return c
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func joinCriteria(cs []Criterion) string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ",")
+}
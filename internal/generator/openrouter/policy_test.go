@@ -0,0 +1,150 @@
+package openrouter
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{"prefer": ["uptime"], "min_uptime": 50, "exclude_providers": ["novita"]}`
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	yamlPath := filepath.Join(t.TempDir(), "policy.yaml")
+	yamlPolicy := "prefer: [price]\nexclude_quantizations:\n  - fp8\n"
+	if err := os.WriteFile(yamlPath, []byte(yamlPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want config
+	}{
+		{
+			name: "defaults",
+			want: defaultConfig(),
+		},
+		{
+			name: "policy file",
+			args: []string{"-policy", path},
+			want: config{
+				policy: Policy{Prefer: []Criterion{PreferUptime}, MinUptime: 50, ExcludeProviders: []string{"novita"}},
+				mode:   modeBest,
+			},
+		},
+		{
+			name: "yaml policy file",
+			args: []string{"-policy", yamlPath},
+			want: config{
+				policy: Policy{Prefer: []Criterion{PreferPrice}, MinUptime: DefaultPolicy().MinUptime, ExcludeQuantizations: []string{"fp8"}},
+				mode:   modeBest,
+			},
+		},
+		{
+			name: "flags after the policy file",
+			args: []string{"-policy", path, "-prefer", "price,tools", "-endpoints", "routes"},
+			want: config{
+				policy: Policy{Prefer: []Criterion{PreferPrice, PreferTools}, MinUptime: 50, ExcludeProviders: []string{"novita"}},
+				mode:   modeRoutes,
+			},
+		},
+		{
+			name: "flags before the policy file",
+			args: []string{"-prefer", "price,tools", "-min-uptime", "80", "-policy", path},
+			want: config{
+				policy: Policy{Prefer: []Criterion{PreferPrice, PreferTools}, MinUptime: 80, ExcludeProviders: []string{"novita"}},
+				mode:   modeBest,
+			},
+		},
+		{
+			name: "filters",
+			args: []string{"-providers", "groq, deepinfra", "-exclude-quantizations", "fp8,int4"},
+			want: config{
+				policy: Policy{
+					Prefer:               DefaultPolicy().Prefer,
+					MinUptime:            DefaultPolicy().MinUptime,
+					Providers:            []string{"groq", "deepinfra"},
+					ExcludeQuantizations: []string{"fp8", "int4"},
+				},
+				mode: modeBest,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("openrouter", flag.ContinueOnError)
+			settings := configFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			got := settings()
+			if got.mode != tt.want.mode || !equalPolicies(got.policy, tt.want.policy) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfigFlagsInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"-prefer", "speed"},
+		{"-min-uptime", "101"},
+		{"-min-uptime", "high"},
+		{"-endpoints", "all"},
+		{"-policy", filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		fs := flag.NewFlagSet("openrouter", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		configFlags(fs)
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want string
+	}{
+		{name: "json", file: "policy.json", data: `{"min_uptime": 50}`},
+		{name: "yaml", file: "policy.yaml", data: "min_uptime: 50\n"},
+		{name: "yml", file: "policy.yml", data: "min_uptime: 50\n"},
+		{name: "empty yaml", file: "policy.yaml"},
+		{name: "json unknown field", file: "policy.json", data: `{"min_uptme": 50}`, want: `unknown field "min_uptme"`},
+		{name: "yaml unknown field", file: "policy.yaml", data: "min_uptme: 50\n", want: "field min_uptme not found"},
+		{name: "yaml unknown criterion", file: "policy.yaml", data: "prefer: [speed]\n", want: `unknown criterion "speed"`},
+		{name: "yaml invalid uptime", file: "policy.yaml", data: "min_uptime: high\n", want: "failed to decode policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadPolicy(path)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("expected an error containing %q, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func equalPolicies(a, b Policy) bool {
+	return slices.Equal(a.Prefer, b.Prefer) && a.MinUptime == b.MinUptime &&
+		slices.Equal(a.Providers, b.Providers) &&
+		slices.Equal(a.ExcludeProviders, b.ExcludeProviders) &&
+		slices.Equal(a.ExcludeQuantizations, b.ExcludeQuantizations)
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/catwalk/internal/providers"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// OverridesDir is the directory of the overrides of the generated configs,
+// relative to the root of the repository.
+const OverridesDir = "internal/generator/overrides"
+
+// Overrides are hand-curated changes to the models of a generated config,
+// keyed by model ID. They are applied on top of the upstream data as JSON
+// merge patches (RFC 7396), so that they survive generator runs, e.g.:
+//
+//	{
+//	  "anthropic/claude-sonnet-4": {"name": "Claude Sonnet 4", "default_max_tokens": 32000},
+//	  "openai/gpt-5": {"default_reasoning_effort": "medium"},
+//	  "example/broken-model": null
+//	}
+//
+// A null override removes the model.
+type Overrides map[string]json.RawMessage
+
+// OverridesFile returns the default path of the overrides of the source.
+func (s Source) OverridesFile() string {
+	return filepath.Join(OverridesDir, string(s.Provider)+".json")
+}
+
+// LoadOverrides loads overrides from a JSON file.
+func LoadOverrides(path string) (Overrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	var o Overrides
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, fmt.Errorf("failed to decode overrides %s: %w", path, err)
+	}
+	return o, nil
+}
+
+// Apply applies the overrides to the models of the provider. Overrides
+// matching no model are skipped, see [Overrides.Unused].
+func (o Overrides) Apply(p *catwalk.Provider) error {
+	for _, id := range slices.Sorted(maps.Keys(o)) {
+		i := slices.IndexFunc(p.Models, func(m catwalk.Model) bool {
+			return m.ID == id
+		})
+		if i < 0 {
+			continue
+		}
+		if isNull(o[id]) {
+			p.Models = slices.Delete(p.Models, i, i+1)
+			continue
+		}
+		patched, err := providers.PatchModel(p.Models[i], o[id])
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		p.Models[i] = patched
+	}
+	return nil
+}
+
+// Unused returns the IDs of the overrides that had no effect on the config,
+// sorted, which are likely stale: overrides removing a model that was not
+// generated, and overrides changing a model that is not in the config, e.g.
+// because it was filtered out.
+func (o Overrides) Unused(generated, config []catwalk.Model) []string {
+	has := func(models []catwalk.Model, id string) bool {
+		return slices.ContainsFunc(models, func(m catwalk.Model) bool {
+			return m.ID == id
+		})
+	}
+	var unused []string
+	for _, id := range slices.Sorted(maps.Keys(o)) {
+		models := config
+		if isNull(o[id]) {
+			models = generated
+		}
+		if !has(models, id) {
+			unused = append(unused, id)
+		}
+	}
+	return unused
+}
+
+func isNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
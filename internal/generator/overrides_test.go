@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestOverrides(t *testing.T) {
+	overrides := Overrides{
+		"a":       json.RawMessage(`{"name": "Model A", "default_reasoning_effort": "medium"}`),
+		"b":       json.RawMessage(`null`),
+		"c":       json.RawMessage(`{"name": "Model C"}`),
+		"d":       json.RawMessage(`{"default_max_tokens": 1000}`),
+		"gone":    json.RawMessage(`{"name": "Gone"}`),
+		"removed": json.RawMessage(`null`),
+	}
+	source := Source{
+		Provider: "test",
+		Generate: func(context.Context, *Env) (catwalk.Provider, error) {
+			return catwalk.Provider{ID: "test", Models: []catwalk.Model{
+				{ID: "a", Name: "a", ContextWindow: 1000, SupportsTools: true},
+				{ID: "b", Name: "b", ContextWindow: 1000, SupportsTools: true},
+				// c is filtered out.
+				{ID: "c", Name: "c", ContextWindow: 1000},
+				{ID: "d", Name: "d", ContextWindow: 4000, SupportsTools: true},
+			}}, nil
+		},
+		Filters: []Filter{SupportsTools},
+	}
+
+	p, err := source.Run(context.Background(), &Env{Overrides: overrides})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []catwalk.Model{
+		{ID: "a", Name: "Model A", ContextWindow: 1000, SupportsTools: true, DefaultReasoningEffort: "medium"},
+		{ID: "d", Name: "d", ContextWindow: 4000, DefaultMaxTokens: 1000, SupportsTools: true},
+	}
+	if !slices.EqualFunc(p.Models, want, func(a, b catwalk.Model) bool {
+		return a.ID == b.ID && a.Name == b.Name && a.DefaultMaxTokens == b.DefaultMaxTokens &&
+			a.DefaultReasoningEffort == b.DefaultReasoningEffort
+	}) {
+		t.Errorf("expected models %+v, got %+v", want, p.Models)
+	}
+
+	generated, _ := source.Generate(context.Background(), nil)
+	unused := overrides.Unused(generated.Models, p.Models)
+	if want := []string{"c", "gone", "removed"}; !slices.Equal(unused, want) {
+		t.Errorf("expected unused overrides %v, got %v", want, unused)
+	}
+}
+
+func TestOverridesInvalid(t *testing.T) {
+	p := catwalk.Provider{Models: []catwalk.Model{{ID: "a"}}}
+	for _, patch := range []string{`{"id": "b"}`, `{"nmae": "A"}`, `{"context_window": "large"}`} {
+		if err := (Overrides{"a": json.RawMessage(patch)}).Apply(&p); err == nil {
+			t.Errorf("expected override %s to be rejected", patch)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"context"
+	"sync"
+)
+
+// Parallel calls fn for every item from at most workers goroutines, and
+// returns the results and errors in the order of the items, so that the
+// output does not depend on the order in which the calls complete. Items
+// that were not processed when ctx is done get its error.
+func Parallel[T, R any](ctx context.Context, workers int, items []T, fn func(context.Context, T) (R, error)) ([]R, []error) {
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = fn(ctx, items[i])
+			}
+		}()
+	}
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return results, errs
+}
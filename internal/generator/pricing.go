@@ -0,0 +1,23 @@
+package generator
+
+import "strconv"
+
+// ParsePrice parses a price, returning 0 when it is missing or invalid.
+func ParsePrice(value string) float64 {
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
+// ParsePerToken parses a price per token, returning it per million tokens,
+// or 0 when it is missing or invalid.
+func ParsePerToken(value string) float64 {
+	return PerMillion(ParsePrice(value))
+}
+
+// PerMillion converts a price per token to a price per million tokens.
+func PerMillion(perToken float64) float64 {
+	return perToken * 1_000_000
+}
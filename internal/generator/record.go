@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// exchange is a recorded response, stored as a JSON file named after the
+// request, see exchangeFile. JSON bodies are stored as is, so that
+// recordings are easy to write by hand.
+type exchange struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// Text is the body of responses that are not JSON.
+	Text string `json:"text,omitempty"`
+}
+
+// recordedHeaders are the response headers worth recording.
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// Recorder is a round tripper saving every response to a directory, for a
+// [Replayer] to serve later.
+type Recorder struct {
+	Dir       string
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := requestKey(req)
+	if err != nil {
+		return nil, err
+	}
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, req.URL, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	e := exchange{
+		Method: req.Method,
+		URL:    redactedURL(req),
+		Status: resp.StatusCode,
+		Header: http.Header{},
+	}
+	for _, name := range recordedHeaders {
+		if v := resp.Header.Values(name); len(v) > 0 {
+			e.Header[name] = v
+		}
+	}
+	if json.Valid(body) {
+		e.Body = body
+	} else {
+		e.Text = string(body)
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, req.URL, err)
+	}
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil { //nolint:gosec
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, req.URL, err)
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, key), data, 0o644); err != nil { //nolint:gosec
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, req.URL, err)
+	}
+	return resp, nil
+}
+
+// Replayer is a round tripper serving the responses saved by a [Recorder],
+// never reaching the network. Requests that were not recorded fail.
+type Replayer struct {
+	Dir string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := requestKey(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(r.Dir, key))
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s: %w", req.Method, req.URL, r.Dir, err)
+	}
+	var e exchange
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("invalid recorded response %s: %w", key, err)
+	}
+
+	body := []byte(e.Text)
+	if len(e.Body) > 0 {
+		body = e.Body
+	}
+	header := e.Header
+	if header == nil {
+		header = http.Header{}
+	}
+	if header.Get("Content-Type") == "" && len(e.Body) > 0 {
+		header.Set("Content-Type", "application/json")
+	}
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// requestKey returns the name of the file holding the response to the
+// request. It is derived from the method and path of the request, leaving
+// out the host so that recordings can be replayed against another base
+// URL, followed by a hash of its query and body when it has any.
+func requestKey(req *http.Request) (string, error) {
+	name := strings.ToLower(req.Method) + "_" + strings.Trim(unsafeChars.ReplaceAllString(req.URL.Path, "_"), "_")
+
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		body, err = io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	if req.URL.RawQuery != "" || len(body) > 0 {
+		sum := sha256.Sum256(append([]byte(req.URL.RawQuery+"\n"), body...))
+		name += "_" + hex.EncodeToString(sum[:4])
+	}
+	return name + ".json", nil
+}
+
+// redactedURL returns the URL of the request without its query, which may
+// hold credentials.
+func redactedURL(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,169 @@
+// Package generator provides the building blocks of the generators that
+// fetch the models of a provider from its upstream API and write its
+// config: a fetcher with retries, price and modality parsers, model
+// filters, a deterministic writer and the registry of the sources.
+//
+// A source is a small adapter registering itself from its package init
+// function:
+//
+//	func init() {
+//		generator.Register(generator.Source{
+//			Provider: catwalk.InferenceProviderOpenRouter,
+//			Generate: generate,
+//			Filters:  []generator.Filter{generator.SupportsTools},
+//		})
+//	}
+//
+// Hand-curated changes to the generated models are kept in [Overrides],
+// applied on every run.
+//
+// Sources build their request URLs with [Env.URL], so that they can be run
+// against a local stand-in of the upstream API. The responses of a run can
+// be saved with a [Recorder] and served again, fully offline, with a
+// [Replayer].
+package generator
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// ConfigDir is the directory of the provider configs, relative to the root
+// of the repository.
+const ConfigDir = "internal/providers/configs"
+
+// Env is what a source needs to generate a provider.
+type Env struct {
+	Fetcher *Fetcher
+	// BaseURL replaces the base URL of the upstream API when set, e.g. to
+	// run a source against a local stand-in.
+	BaseURL string
+	// Concurrency is the maximum number of concurrent requests of the
+	// sources fetching the details of every model.
+	Concurrency int
+	// Existing is the current config of the provider, empty when there is
+	// none. Sources may keep its models when the upstream data cannot be
+	// trusted.
+	Existing catwalk.Provider
+	// Overrides are applied to the generated models.
+	Overrides Overrides
+	// Verbose enables the logging of every generated model.
+	Verbose bool
+}
+
+// URL returns the URL of the given path of the upstream API, whose base
+// URL defaults to base.
+func (e *Env) URL(base, path string) string {
+	if e.BaseURL != "" {
+		base = e.BaseURL
+	}
+	return strings.TrimSuffix(base, "/") + path
+}
+
+// Debugf logs a message in verbose mode.
+func (e *Env) Debugf(format string, args ...any) {
+	if e.Verbose {
+		log.Printf(format, args...)
+	}
+}
+
+// GenerateFunc fetches the models of a provider and builds it.
+type GenerateFunc func(ctx context.Context, env *Env) (catwalk.Provider, error)
+
+// Source generates the config of a provider from its upstream API.
+type Source struct {
+	// Provider is the ID of the generated provider.
+	Provider catwalk.InferenceProvider
+	// Generate fetches the models and builds the provider, with the default
+	// settings of the source.
+	Generate GenerateFunc
+	// Filters drop the generated models failing any of them. Models without
+	// a context window are always dropped.
+	Filters []Filter
+	// Flags registers the settings of the source as flags, parsed from the
+	// arguments following the provider on the command line, and returns the
+	// function generating the provider with the parsed settings. It may be
+	// nil.
+	Flags func(fs *flag.FlagSet) GenerateFunc
+}
+
+// WithFlags registers the flags of the source on fs, and returns the source
+// generating the provider with their values once fs is parsed.
+func (s Source) WithFlags(fs *flag.FlagSet) Source {
+	if s.Flags != nil {
+		s.Generate = s.Flags(fs)
+	}
+	return s
+}
+
+// Output returns the default path of the generated config.
+func (s Source) Output() string {
+	return filepath.Join(ConfigDir, string(s.Provider)+".json")
+}
+
+// Run generates the provider, applying the overrides of the environment,
+// then filtering and sorting its models. Overrides left without effect are
+// reported.
+func (s Source) Run(ctx context.Context, env *Env) (catwalk.Provider, error) {
+	p, err := s.Generate(ctx, env)
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to generate %s: %w", s.Provider, err)
+	}
+	generated := slices.Clone(p.Models)
+	if err := env.Overrides.Apply(&p); err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to apply the overrides of %s: %w", s.Provider, err)
+	}
+	p.Models = Keep(p.Models, append([]Filter{HasContextWindow}, s.Filters...)...)
+	SortModels(p.Models)
+	for _, id := range env.Overrides.Unused(generated, p.Models) {
+		log.Printf("Warning: Override of %s matches no model of the config, it may be removed", id)
+	}
+	return p, nil
+}
+
+var (
+	sourcesMu sync.Mutex
+	sources   = map[catwalk.InferenceProvider]Source{}
+)
+
+// Register registers a source. It panics when a source is already
+// registered for the same provider.
+func Register(s Source) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, ok := sources[s.Provider]; ok {
+		panic("generator: source registered twice for " + string(s.Provider))
+	}
+	sources[s.Provider] = s
+}
+
+// Lookup returns the source of the given provider.
+func Lookup(provider catwalk.InferenceProvider) (Source, bool) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	s, ok := sources[provider]
+	return s, ok
+}
+
+// Sources returns the registered sources, sorted by provider.
+func Sources() []Source {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	all := make([]Source, 0, len(sources))
+	for _, s := range sources {
+		all = append(all, s)
+	}
+	slices.SortFunc(all, func(a, b Source) int {
+		return cmp.Compare(a.Provider, b.Provider)
+	})
+	return all
+}
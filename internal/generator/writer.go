@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// SortModels sorts models by name, then by ID, so that generated configs
+// do not change between runs when the upstream models do not.
+func SortModels(models []catwalk.Model) {
+	slices.SortStableFunc(models, func(a, b catwalk.Model) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+	})
+}
+
+// Encode returns the config of the provider, formatted like the configs
+// shipped with catwalk.
+func Encode(p catwalk.Provider) ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode provider %s: %w", p.ID, err)
+	}
+	return data, nil
+}
+
+// WriteFile writes the config of the provider to path. The file is replaced
+// atomically, so that a failed run never leaves a truncated config behind.
+func WriteFile(path string, p catwalk.Provider) error {
+	data, err := Encode(p)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
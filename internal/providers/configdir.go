@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// ConfigDirEnv is the environment variable holding the directory of the
+// extra provider configs.
+const ConfigDirEnv = "CATWALK_CONFIG_DIR"
+
+// LoadDir loads the provider configs found in dir, i.e. its *.json files
+// other than overlays, sorted by provider ID. Providers whose config is
+// invalid are left out and their errors returned.
+func LoadDir(dir string) ([]catwalk.Provider, error) {
+	files, _, err := configFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var providers []catwalk.Provider
+	var errs []error
+	seen := make(map[catwalk.InferenceProvider]string, len(files))
+	for _, file := range files {
+		p, err := loadConfigFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[p.ID]; ok {
+			errs = append(errs, fmt.Errorf("%s: provider %q is already defined in %s", file, p.ID, other))
+			continue
+		}
+		seen[p.ID] = file
+		providers = append(providers, p)
+	}
+
+	slices.SortFunc(providers, func(a, b catwalk.Provider) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return providers, errors.Join(errs...)
+}
+
+// LoadWithDir loads the embedded providers merged with the ones found in
+// dir, see [Merge], then applies the overlays found in dir, sorted by file
+// name, and the given overlay files, in order. An empty dir loads the
+// embedded providers only.
+func LoadWithDir(dir string, overlays ...string) ([]catwalk.Provider, error) {
+	providers, err := Load()
+	errs := []error{err}
+	if dir != "" {
+		extra, err := LoadDir(dir)
+		errs = append(errs, err)
+		providers = Merge(providers, extra)
+		if _, files, err := configFiles(dir); err == nil {
+			overlays = append(files, overlays...)
+		}
+	}
+	if len(overlays) > 0 {
+		o, err := LoadOverlays(overlays...)
+		errs = append(errs, err)
+		providers, err = ApplyOverlays(providers, o)
+		errs = append(errs, err)
+	}
+	return providers, errors.Join(errs...)
+}
+
+// Merge merges extra providers into base: an extra provider replaces the
+// base provider with the same ID, in place, and the other ones are
+// appended in the order they come in.
+func Merge(base, extra []catwalk.Provider) []catwalk.Provider {
+	merged := slices.Clone(base)
+	for _, p := range extra {
+		i := slices.IndexFunc(merged, func(b catwalk.Provider) bool {
+			return b.ID == p.ID
+		})
+		if i >= 0 {
+			merged[i] = p
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// configFiles returns the provider configs and the overlays found in dir,
+// sorted by file name.
+func configFiles(dir string) (providerFiles, overlayFiles []string, err error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("invalid config directory: %s is not a directory", dir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list configs: %w", err)
+	}
+	for _, file := range files {
+		if isOverlayFile(file) {
+			overlayFiles = append(overlayFiles, file)
+		} else {
+			providerFiles = append(providerFiles, file)
+		}
+	}
+	return providerFiles, overlayFiles, nil
+}
+
+func loadConfigFile(path string) (catwalk.Provider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	p, err := DecodeConfig(data)
+	if err == nil {
+		err = ValidateCustom(p)
+	}
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
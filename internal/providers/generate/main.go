@@ -0,0 +1,224 @@
+// Package main generates the inference provider constants of
+// pkg/catwalk and the provider registry of internal/providers from the
+// provider configs, so that adding a config is all it takes to add a
+// provider.
+//
+// It is run with go generate from internal/providers:
+//
+//	go generate ./internal/providers
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// priority is the order of the providers in the registry. Providers missing
+// from it come after, sorted by ID.
+var priority = []string{
+	"anthropic",
+	"openai",
+	"gemini",
+	"azure",
+	"bedrock",
+	"vertexai",
+	"xai",
+	"zai",
+	"groq",
+	"openrouter",
+	"cerebras",
+	"venice",
+	"chutes",
+	"deepseek",
+	"apipie",
+	"huggingface",
+	"aihubmix",
+}
+
+// identifiers are the Go names of the providers whose name is not their ID
+// with an upper case first letter.
+var identifiers = map[string]string{
+	"openai":      "OpenAI",
+	"vertexai":    "VertexAI",
+	"xai":         "XAI",
+	"zai":         "ZAI",
+	"groq":        "GROQ",
+	"openrouter":  "OpenRouter",
+	"huggingface": "HuggingFace",
+	"aihubmix":    "AIHubMix",
+	"deepseek":    "DeepSeek",
+	"apipie":      "APIpie",
+}
+
+// types are the Go names of the provider types.
+var types = map[string]string{
+	"openai":    "TypeOpenAI",
+	"anthropic": "TypeAnthropic",
+	"gemini":    "TypeGemini",
+	"azure":     "TypeAzure",
+	"bedrock":   "TypeBedrock",
+	"vertexai":  "TypeVertexAI",
+}
+
+// config holds the fields of a provider config the generator needs. The
+// generator does not depend on pkg/catwalk, which uses its output.
+type config struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	APIKey      string `json:"api_key"`
+	APIEndpoint string `json:"api_endpoint"`
+}
+
+// provider is a provider as seen by the templates.
+type provider struct {
+	ID             string
+	Ident          string
+	Type           string
+	APIKeyEnv      string
+	APIEndpointEnv string
+}
+
+const header = "// Code generated by internal/providers/generate; DO NOT EDIT.\n\n"
+
+var constantsTemplate = template.Must(template.New("constants").Parse(header + `package catwalk
+
+// All the inference providers supported by the system.
+const (
+{{- range .}}
+	InferenceProvider{{.Ident}} InferenceProvider = {{printf "%q" .ID}}
+{{- end}}
+)
+
+// KnownProviders returns all the known inference providers.
+func KnownProviders() []InferenceProvider {
+	return []InferenceProvider{
+{{- range .}}
+		InferenceProvider{{.Ident}},
+{{- end}}
+	}
+}
+`))
+
+var registryTemplate = template.Must(template.New("registry").Parse(header + `package providers
+
+import "github.com/charmbracelet/catwalk/pkg/catwalk"
+
+var providerRegistry = []registration{
+{{- range .}}
+	{
+		id:   catwalk.InferenceProvider{{.Ident}},
+		typ:  catwalk.{{.Type}},
+{{- if .APIKeyEnv}}
+		apiKeyEnv: {{printf "%q" .APIKeyEnv}},
+{{- end}}
+{{- if .APIEndpointEnv}}
+		apiEndpointEnv: {{printf "%q" .APIEndpointEnv}},
+{{- end}}
+	},
+{{- end}}
+}
+`))
+
+func main() {
+	configs := flag.String("configs", "configs", "directory of the provider configs")
+	registry := flag.String("registry", "registry_gen.go", "output file of the provider registry")
+	constants := flag.String("constants", "../../pkg/catwalk/providers_gen.go", "output file of the provider constants")
+	flag.Parse()
+
+	providers, err := readProviders(*configs)
+	if err != nil {
+		log.Fatal("Error reading provider configs: ", err)
+	}
+	if err := render(constantsTemplate, providers, *constants); err != nil {
+		log.Fatal("Error generating provider constants: ", err)
+	}
+	if err := render(registryTemplate, providers, *registry); err != nil {
+		log.Fatal("Error generating provider registry: ", err)
+	}
+}
+
+func readProviders(dir string) ([]provider, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	providers := make([]provider, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		var p config
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if want := p.ID + ".json"; filepath.Base(file) != want {
+			return nil, fmt.Errorf("%s: config of provider %q must be named %s", file, p.ID, want)
+		}
+		typ, ok := types[p.Type]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown provider type %q", file, p.Type)
+		}
+		providers = append(providers, provider{
+			ID:             p.ID,
+			Ident:          identifier(p.ID),
+			Type:           typ,
+			APIKeyEnv:      envName(p.APIKey),
+			APIEndpointEnv: envName(p.APIEndpoint),
+		})
+	}
+
+	slices.SortFunc(providers, func(a, b provider) int {
+		return cmp.Or(cmp.Compare(rank(a.ID), rank(b.ID)), strings.Compare(a.ID, b.ID))
+	})
+	return providers, nil
+}
+
+func rank(id string) int {
+	if i := slices.Index(priority, id); i >= 0 {
+		return i
+	}
+	return len(priority)
+}
+
+func identifier(id string) string {
+	if ident, ok := identifiers[id]; ok {
+		return ident
+	}
+	r := []rune(id)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// envName returns the name of the environment variable a config value
+// refers to, if any.
+func envName(value string) string {
+	if name, ok := strings.CutPrefix(value, "$"); ok {
+		return name
+	}
+	return ""
+}
+
+func render(t *template.Template, providers []provider, path string) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, providers); err != nil {
+		return err //nolint:wrapcheck
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return os.WriteFile(path, src, 0o644) //nolint:gosec,wrapcheck
+}
@@ -0,0 +1,254 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// OverlaySuffix is the suffix of the overlay files found in a config
+// directory.
+const OverlaySuffix = ".overlay.json"
+
+// Overlay changes a provider. An overlay file holds a list of overlays,
+// e.g.:
+//
+//	[
+//	  {
+//	    "provider": "azure",
+//	    "patch": {"api_endpoint": "https://example.openai.azure.com"},
+//	    "models": {
+//	      "gpt-4o": {"default_max_tokens": 8000},
+//	      "o1-mini": null,
+//	      "my-deployment": {"name": "My Deployment", "context_window": 128000}
+//	    }
+//	  },
+//	  {"provider": "venice", "remove": true}
+//	]
+type Overlay struct {
+	// Provider is the ID of the provider to change.
+	Provider catwalk.InferenceProvider `json:"provider"`
+	// Remove removes the provider from the catalog.
+	Remove bool `json:"remove,omitempty"`
+	// Patch is a JSON merge patch (RFC 7396) applied to the provider. It
+	// cannot change its ID or models.
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// Models are JSON merge patches applied to the models of the provider,
+	// by model ID. A null patch removes the model, and a patch for a model
+	// the provider does not have adds it.
+	Models map[string]json.RawMessage `json:"models,omitempty"`
+}
+
+// LoadOverlays loads the overlays of the given files, in order.
+func LoadOverlays(paths ...string) ([]Overlay, error) {
+	var overlays []Overlay
+	var errs []error
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read overlay: %w", err))
+			continue
+		}
+		var o []Overlay
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&o); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid overlay: %w", path, err))
+			continue
+		}
+		overlays = append(overlays, o...)
+	}
+	return overlays, errors.Join(errs...)
+}
+
+// ApplyOverlays applies the overlays to the providers, in order. Overlays
+// that do not apply, or that leave their provider invalid, are skipped and
+// their errors returned.
+func ApplyOverlays(providers []catwalk.Provider, overlays []Overlay) ([]catwalk.Provider, error) {
+	providers = slices.Clone(providers)
+	var errs []error
+	for _, o := range overlays {
+		i := slices.IndexFunc(providers, func(p catwalk.Provider) bool {
+			return p.ID == o.Provider
+		})
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("overlay of provider %q: no such provider", o.Provider))
+			continue
+		}
+		if o.Remove {
+			providers = slices.Delete(providers, i, i+1)
+			continue
+		}
+		p, err := o.apply(providers[i])
+		if err == nil {
+			err = ValidateCustom(p)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("overlay of provider %q: %w", o.Provider, err))
+			continue
+		}
+		providers[i] = p
+	}
+	return providers, errors.Join(errs...)
+}
+
+func (o Overlay) apply(p catwalk.Provider) (catwalk.Provider, error) {
+	if len(o.Patch) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(o.Patch, &fields); err != nil {
+			return p, fmt.Errorf("invalid patch: %w", err)
+		}
+		if _, ok := fields["id"]; ok {
+			return p, errors.New("patch cannot change the provider id")
+		}
+		if _, ok := fields["models"]; ok {
+			return p, errors.New("patch cannot change the models, use models instead")
+		}
+		var patched catwalk.Provider
+		if err := patchJSON(p, o.Patch, &patched); err != nil {
+			return p, err
+		}
+		p = patched
+	}
+
+	models := slices.Clone(p.Models)
+	for _, id := range slices.Sorted(maps.Keys(o.Models)) {
+		patch := o.Models[id]
+		i := slices.IndexFunc(models, func(m catwalk.Model) bool {
+			return m.ID == id
+		})
+		if isNull(patch) {
+			if i < 0 {
+				return p, fmt.Errorf("cannot remove model %q: no such model", id)
+			}
+			models = slices.Delete(models, i, i+1)
+			continue
+		}
+
+		model := catwalk.Model{ID: id}
+		if i >= 0 {
+			model = models[i]
+		}
+		patched, err := PatchModel(model, patch)
+		if err != nil {
+			return p, err
+		}
+		if i >= 0 {
+			models[i] = patched
+		} else {
+			models = append(models, patched)
+		}
+	}
+	p.Models = models
+	return p, nil
+}
+
+// PatchModel applies a JSON merge patch to a model. The patch cannot change
+// the ID of the model.
+//
+// The legacy cost fields and the base prices of the pricing are kept in
+// sync: a patch changing only one of them changes the other accordingly,
+// and a patch changing both must keep them consistent.
+func PatchModel(m catwalk.Model, patch json.RawMessage) (catwalk.Model, error) {
+	var patched catwalk.Model
+	if err := patchJSON(m, patch, &patched); err != nil {
+		return m, fmt.Errorf("model %q: %w", m.ID, err)
+	}
+	if patched.ID != m.ID {
+		return m, fmt.Errorf("model %q: patch cannot change the model id", m.ID)
+	}
+	if patched.Pricing == nil {
+		return patched, nil
+	}
+
+	legacy := legacyPrices(patched)
+	base := basePrices(*patched.Pricing)
+	legacyChanged := legacyPrices(m) != legacy
+	pricingChanged := m.Pricing == nil || basePrices(*m.Pricing) != base
+	switch {
+	case legacy == base:
+	case legacyChanged && !pricingChanged:
+		patched.Pricing.Input = legacy.Input
+		patched.Pricing.Output = legacy.Output
+		patched.Pricing.CacheRead = legacy.CacheRead
+		patched.Pricing.CacheWrite = legacy.CacheWrite
+	case pricingChanged && !legacyChanged:
+		patched.SetPricing(*patched.Pricing)
+	default:
+		return m, fmt.Errorf("model %q: patch sets legacy costs %+v inconsistent with the pricing %+v", m.ID, legacy, base)
+	}
+	return patched, nil
+}
+
+// patchJSON applies a JSON merge patch to the JSON encoding of v, and
+// decodes the result into out, rejecting unknown fields.
+func patchJSON(v any, patch json.RawMessage, out any) error {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	merged, err := MergePatch(doc, patch)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+	return nil
+}
+
+// MergePatch applies a JSON merge patch (RFC 7396) to a JSON document.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p any
+	if err := decodeNumbers(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	if err := decodeNumbers(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	return json.Marshal(mergePatch(target, p)) //nolint:wrapcheck
+}
+
+func mergePatch(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = make(map[string]any, len(fields))
+	}
+	for k, v := range fields {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = mergePatch(result[k], v)
+	}
+	return result
+}
+
+// decodeNumbers decodes JSON keeping numbers as they are written.
+func decodeNumbers(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v) //nolint:wrapcheck
+}
+
+func isNull(data json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+func isOverlayFile(path string) bool {
+	return strings.HasSuffix(filepath.Base(path), OverlaySuffix)
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestPatchModelPrices(t *testing.T) {
+	priced := validModel("model")
+	priced.SetPricing(catwalk.Pricing{
+		TokenPrices: catwalk.TokenPrices{Input: 1, Output: 2, CacheRead: 0.1},
+		PerRequest:  0.01,
+	})
+	tests := []struct {
+		name  string
+		model catwalk.Model
+		patch string
+		want  catwalk.TokenPrices
+		err   string
+	}{
+		{
+			name:  "legacy costs",
+			model: priced,
+			patch: `{"cost_per_1m_in": 3, "cost_per_1m_out_cached": 0.3}`,
+			want:  catwalk.TokenPrices{Input: 3, Output: 2, CacheRead: 0.3},
+		},
+		{
+			name:  "pricing",
+			model: priced,
+			patch: `{"pricing": {"output": 4}}`,
+			want:  catwalk.TokenPrices{Input: 1, Output: 4, CacheRead: 0.1},
+		},
+		{
+			name:  "consistent legacy costs and pricing",
+			model: priced,
+			patch: `{"cost_per_1m_out": 4, "pricing": {"output": 4}}`,
+			want:  catwalk.TokenPrices{Input: 1, Output: 4, CacheRead: 0.1},
+		},
+		{
+			name:  "inconsistent legacy costs and pricing",
+			model: priced,
+			patch: `{"cost_per_1m_out": 5, "pricing": {"output": 4}}`,
+			err:   "inconsistent with the pricing",
+		},
+		{
+			name:  "new pricing",
+			model: validModel("model"),
+			patch: `{"pricing": {"input": 1, "output": 2}}`,
+			want:  catwalk.TokenPrices{Input: 1, Output: 2},
+		},
+		{
+			name:  "legacy costs without pricing",
+			model: validModel("model"),
+			patch: `{"cost_per_1m_in": 1}`,
+			want:  catwalk.TokenPrices{Input: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := PatchModel(tt.model, json.RawMessage(tt.patch))
+			checkError(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			if got := m.Prices().TokenPrices; got != tt.want {
+				t.Errorf("expected pricing %+v, got %+v", tt.want, got)
+			}
+			if got := legacyPrices(m); got != tt.want {
+				t.Errorf("expected legacy costs %+v, got %+v", tt.want, got)
+			}
+			if tt.model.Pricing != nil && m.Pricing.PerRequest != tt.model.Pricing.PerRequest {
+				t.Errorf("expected the per request price to be kept, got %v", m.Pricing.PerRequest)
+			}
+		})
+	}
+}
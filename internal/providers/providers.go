@@ -1,170 +1,102 @@
 // Package providers provides a registry of inference providers
 package providers
 
+//go:generate go run ./generate
+
 import (
-	_ "embed"
-	"encoding/json"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"path"
+	"slices"
 
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 )
 
-//go:embed configs/openai.json
-var openAIConfig []byte
-
-//go:embed configs/anthropic.json
-var anthropicConfig []byte
-
-//go:embed configs/gemini.json
-var geminiConfig []byte
-
-//go:embed configs/openrouter.json
-var openRouterConfig []byte
-
-//go:embed configs/azure.json
-var azureConfig []byte
-
-//go:embed configs/vertexai.json
-var vertexAIConfig []byte
-
-//go:embed configs/xai.json
-var xAIConfig []byte
-
-//go:embed configs/zai.json
-var zAIConfig []byte
-
-//go:embed configs/bedrock.json
-var bedrockConfig []byte
-
-//go:embed configs/groq.json
-var groqConfig []byte
-
-//go:embed configs/cerebras.json
-var cerebrasConfig []byte
-
-//go:embed configs/venice.json
-var veniceConfig []byte
-
-//go:embed configs/chutes.json
-var chutesConfig []byte
-
-//go:embed configs/deepseek.json
-var deepSeekConfig []byte
-
-//go:embed configs/apipie.json
-var apipieConfig []byte
+//go:embed configs/*.json
+var configs embed.FS
 
-//go:embed configs/huggingface.json
-var huggingFaceConfig []byte
-
-//go:embed configs/aihubmix.json
-var aiHubMixConfig []byte
-
-// ProviderFunc is a function that returns a Provider.
-type ProviderFunc func() catwalk.Provider
-
-var providerRegistry = []ProviderFunc{
-	anthropicProvider,
-	openAIProvider,
-	geminiProvider,
-	azureProvider,
-	bedrockProvider,
-	vertexAIProvider,
-	xAIProvider,
-	zAIProvider,
-	groqProvider,
-	openRouterProvider,
-	cerebrasProvider,
-	veniceProvider,
-	chutesProvider,
-	deepSeekProvider,
-	apipieProvider,
-	huggingFaceProvider,
-	aiHubMixProvider,
+// registration describes a provider shipped with catwalk. The registry is
+// generated from the configs by go generate, see generate/main.go.
+type registration struct {
+	id             catwalk.InferenceProvider
+	typ            catwalk.Type
+	apiKeyEnv      string
+	apiEndpointEnv string
 }
 
-// GetAll returns all registered providers.
-func GetAll() []catwalk.Provider {
-	providers := make([]catwalk.Provider, 0, len(providerRegistry))
-	for _, providerFunc := range providerRegistry {
-		providers = append(providers, providerFunc())
-	}
-	return providers
+func (r registration) file() string {
+	return "configs/" + string(r.id) + ".json"
 }
 
-func loadProviderFromConfig(configData []byte) catwalk.Provider {
-	var p catwalk.Provider
-	if err := json.Unmarshal(configData, &p); err != nil {
-		log.Printf("Error loading provider config: %v", err)
-		return catwalk.Provider{}
+// load loads the config of the provider, checking that the registry is up
+// to date with it.
+func (r registration) load() (catwalk.Provider, error) {
+	name := path.Base(r.file())
+	data, err := configs.ReadFile(r.file())
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("%s: %w", name, err)
 	}
-	return p
-}
-
-func openAIProvider() catwalk.Provider {
-	return loadProviderFromConfig(openAIConfig)
-}
-
-func anthropicProvider() catwalk.Provider {
-	return loadProviderFromConfig(anthropicConfig)
-}
-
-func geminiProvider() catwalk.Provider {
-	return loadProviderFromConfig(geminiConfig)
-}
-
-func azureProvider() catwalk.Provider {
-	return loadProviderFromConfig(azureConfig)
-}
-
-func bedrockProvider() catwalk.Provider {
-	return loadProviderFromConfig(bedrockConfig)
-}
-
-func vertexAIProvider() catwalk.Provider {
-	return loadProviderFromConfig(vertexAIConfig)
-}
-
-func xAIProvider() catwalk.Provider {
-	return loadProviderFromConfig(xAIConfig)
-}
-
-func zAIProvider() catwalk.Provider {
-	return loadProviderFromConfig(zAIConfig)
-}
-
-func openRouterProvider() catwalk.Provider {
-	return loadProviderFromConfig(openRouterConfig)
-}
-
-func groqProvider() catwalk.Provider {
-	return loadProviderFromConfig(groqConfig)
-}
-
-func cerebrasProvider() catwalk.Provider {
-	return loadProviderFromConfig(cerebrasConfig)
-}
-
-func veniceProvider() catwalk.Provider {
-	return loadProviderFromConfig(veniceConfig)
+	p, err := loadProviderFromConfig(name, data)
+	if err != nil {
+		return catwalk.Provider{}, err
+	}
+	if p.ID != r.id || p.Type != r.typ || envRef(r.apiKeyEnv) != p.APIKey ||
+		(r.apiEndpointEnv != "" && envRef(r.apiEndpointEnv) != p.APIEndpoint) {
+		return catwalk.Provider{}, fmt.Errorf("%s: config does not match the provider registry, run go generate ./internal/providers", name)
+	}
+	return p, nil
 }
 
-func chutesProvider() catwalk.Provider {
-	return loadProviderFromConfig(chutesConfig)
+func envRef(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "$" + name
 }
 
-func deepSeekProvider() catwalk.Provider {
-	return loadProviderFromConfig(deepSeekConfig)
-}
+// Load returns all registered providers, along with the errors of the
+// providers whose config is invalid, which are left out.
+func Load() ([]catwalk.Provider, error) {
+	providers := make([]catwalk.Provider, 0, len(providerRegistry))
+	var errs []error
+	for _, r := range providerRegistry {
+		p, err := r.load()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		providers = append(providers, p)
+	}
 
-func apipieProvider() catwalk.Provider {
-	return loadProviderFromConfig(apipieConfig)
+	files, _ := fs.Glob(configs, "configs/*.json")
+	for _, file := range files {
+		if !slices.ContainsFunc(providerRegistry, func(r registration) bool { return r.file() == file }) {
+			errs = append(errs, fmt.Errorf("%s: provider is not in the registry, run go generate ./internal/providers", path.Base(file)))
+		}
+	}
+	return providers, errors.Join(errs...)
 }
 
-func huggingFaceProvider() catwalk.Provider {
-	return loadProviderFromConfig(huggingFaceConfig)
+// GetAll returns all registered providers, logging and leaving out the ones
+// whose config is invalid.
+func GetAll() []catwalk.Provider {
+	providers, err := Load()
+	if err != nil {
+		log.Printf("Error loading provider configs: %v", err)
+	}
+	return providers
 }
 
-func aiHubMixProvider() catwalk.Provider {
-	return loadProviderFromConfig(aiHubMixConfig)
+func loadProviderFromConfig(name string, configData []byte) (catwalk.Provider, error) {
+	p, err := DecodeConfig(configData)
+	if err == nil {
+		err = Validate(p)
+	}
+	if err != nil {
+		return catwalk.Provider{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return p, nil
 }
@@ -0,0 +1,95 @@
+// Code generated by internal/providers/generate; DO NOT EDIT.
+
+package providers
+
+import "github.com/charmbracelet/catwalk/pkg/catwalk"
+
+var providerRegistry = []registration{
+	{
+		id:             catwalk.InferenceProviderAnthropic,
+		typ:            catwalk.TypeAnthropic,
+		apiKeyEnv:      "ANTHROPIC_API_KEY",
+		apiEndpointEnv: "ANTHROPIC_API_ENDPOINT",
+	},
+	{
+		id:             catwalk.InferenceProviderOpenAI,
+		typ:            catwalk.TypeOpenAI,
+		apiKeyEnv:      "OPENAI_API_KEY",
+		apiEndpointEnv: "OPENAI_API_ENDPOINT",
+	},
+	{
+		id:             catwalk.InferenceProviderGemini,
+		typ:            catwalk.TypeGemini,
+		apiKeyEnv:      "GEMINI_API_KEY",
+		apiEndpointEnv: "GEMINI_API_ENDPOINT",
+	},
+	{
+		id:             catwalk.InferenceProviderAzure,
+		typ:            catwalk.TypeAzure,
+		apiKeyEnv:      "AZURE_OPENAI_API_KEY",
+		apiEndpointEnv: "AZURE_OPENAI_API_ENDPOINT",
+	},
+	{
+		id:  catwalk.InferenceProviderBedrock,
+		typ: catwalk.TypeBedrock,
+	},
+	{
+		id:  catwalk.InferenceProviderVertexAI,
+		typ: catwalk.TypeVertexAI,
+	},
+	{
+		id:        catwalk.InferenceProviderXAI,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "XAI_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderZAI,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "ZAI_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderGROQ,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "GROQ_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderOpenRouter,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "OPENROUTER_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderCerebras,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "CEREBRAS_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderVenice,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "VENICE_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderChutes,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "CHUTES_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderDeepSeek,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "DEEPSEEK_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderAPIpie,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "APIPIE_API_KEY",
+	},
+	{
+		id:        catwalk.InferenceProviderHuggingFace,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "HF_TOKEN",
+	},
+	{
+		id:        catwalk.InferenceProviderAIHubMix,
+		typ:       catwalk.TypeOpenAI,
+		apiKeyEnv: "AIHUBMIX_API_KEY",
+	},
+}
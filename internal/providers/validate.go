@@ -0,0 +1,172 @@
+package providers
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// DecodeConfig decodes a provider config, rejecting unknown fields and
+// trailing data.
+func DecodeConfig(data []byte) (catwalk.Provider, error) {
+	var p catwalk.Provider
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return catwalk.Provider{}, fmt.Errorf("invalid provider config: %w", err)
+	}
+	if dec.More() {
+		return catwalk.Provider{}, errors.New("invalid provider config: trailing data after provider")
+	}
+	return p, nil
+}
+
+// Validate checks a provider shipped with catwalk for consistency,
+// returning all the problems found joined in a single error.
+func Validate(p catwalk.Provider) error {
+	return validate(p, true)
+}
+
+// ValidateCustom checks a provider loaded from a config directory for
+// consistency. Unlike the providers shipped with catwalk, it may have any
+// ID.
+func ValidateCustom(p catwalk.Provider) error {
+	return validate(p, false)
+}
+
+// ValidateModel checks a model for consistency, returning all the problems
+// found joined in a single error.
+func ValidateModel(m catwalk.Model) error {
+	return errors.Join(validateModel(m)...)
+}
+
+func validate(p catwalk.Provider, known bool) error {
+	var errs []error
+	report := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if p.ID == "" {
+		report("provider has no id")
+	} else if known && !slices.Contains(catwalk.KnownProviders(), p.ID) {
+		report("provider %q is not a known provider", p.ID)
+	}
+	if p.Name == "" {
+		report("provider %q has no name", p.ID)
+	}
+	if len(p.Models) == 0 {
+		report("provider %q has no models", p.ID)
+	}
+
+	seen := make(map[string]bool, len(p.Models))
+	for _, m := range p.Models {
+		if m.ID == "" {
+			report("model %q has no id", m.Name)
+			continue
+		}
+		if seen[m.ID] {
+			report("model %q is defined more than once", m.ID)
+		}
+		seen[m.ID] = true
+		errs = append(errs, validateModel(m)...)
+	}
+
+	if p.DefaultLargeModelID != "" && !seen[p.DefaultLargeModelID] {
+		report("default large model %q is not a model of the provider", p.DefaultLargeModelID)
+	}
+	if p.DefaultSmallModelID != "" && !seen[p.DefaultSmallModelID] {
+		report("default small model %q is not a model of the provider", p.DefaultSmallModelID)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateModel(m catwalk.Model) []error {
+	var errs []error
+	report := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("model %q: "+format, append([]any{m.ID}, args...)...))
+	}
+
+	if m.ContextWindow <= 0 {
+		report("context window must be positive, got %d", m.ContextWindow)
+	}
+	if m.DefaultMaxTokens < 0 {
+		report("default max tokens must not be negative, got %d", m.DefaultMaxTokens)
+	}
+	if m.ContextWindow > 0 && m.DefaultMaxTokens > m.ContextWindow {
+		report("default max tokens %d exceed the context window %d", m.DefaultMaxTokens, m.ContextWindow)
+	}
+	if m.HasReasoningEffort && !m.CanReason {
+		report("has reasoning efforts but cannot reason")
+	}
+
+	if min(m.CostPer1MIn, m.CostPer1MOut, m.CostPer1MInCached, m.CostPer1MOutCached) < 0 {
+		report("prices must not be negative")
+	} else if m.Pricing != nil && !validPricing(*m.Pricing) {
+		report("prices must not be negative")
+	}
+	if m.Pricing != nil && legacyPrices(m) != basePrices(*m.Pricing) {
+		report("legacy costs %+v do not match the pricing %+v", legacyPrices(m), basePrices(*m.Pricing))
+	}
+	if m.Pricing != nil && !slices.IsSortedFunc(m.Pricing.Tiers, func(a, b catwalk.PricingTier) int {
+		return cmp.Compare(a.AboveTokens, b.AboveTokens)
+	}) {
+		report("pricing tiers must be sorted by increasing size")
+	}
+	if m.Upstream != nil && m.Upstream.Provider == "" {
+		report("upstream must have a provider")
+	}
+	for _, r := range m.Routes {
+		if r.Provider == "" {
+			report("routes must have a provider")
+		}
+		if r.Pricing != nil && !validPricing(*r.Pricing) {
+			report("route %s: prices must not be negative", r.Provider)
+		}
+	}
+
+	return errs
+}
+
+// legacyPrices returns the token prices set by the legacy cost fields of
+// the model.
+func legacyPrices(m catwalk.Model) catwalk.TokenPrices {
+	return catwalk.TokenPrices{
+		Input:      m.CostPer1MIn,
+		Output:     m.CostPer1MOut,
+		CacheRead:  m.CostPer1MOutCached,
+		CacheWrite: m.CostPer1MInCached,
+	}
+}
+
+// basePrices returns the base token prices of the pricing that the legacy
+// cost fields mirror.
+func basePrices(p catwalk.Pricing) catwalk.TokenPrices {
+	return catwalk.TokenPrices{
+		Input:      p.Input,
+		Output:     p.Output,
+		CacheRead:  p.CacheRead,
+		CacheWrite: p.CacheWrite,
+	}
+}
+
+func validPricing(p catwalk.Pricing) bool {
+	if !validTokenPrices(p.TokenPrices) || min(p.PerRequest, p.PerImage, p.AudioInput) < 0 {
+		return false
+	}
+	for _, tier := range p.Tiers {
+		if tier.AboveTokens < 0 || !validTokenPrices(tier.TokenPrices) {
+			return false
+		}
+	}
+	return true
+}
+
+func validTokenPrices(t catwalk.TokenPrices) bool {
+	return min(t.Input, t.Output, t.CacheRead, t.CacheWrite, t.Reasoning) >= 0
+}
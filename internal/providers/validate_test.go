@@ -0,0 +1,282 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func validModel(id string) catwalk.Model {
+	return catwalk.Model{
+		ID:               id,
+		Name:             id,
+		ContextWindow:    128_000,
+		DefaultMaxTokens: 4_096,
+	}
+}
+
+func validProvider() catwalk.Provider {
+	return catwalk.Provider{
+		ID:                  catwalk.InferenceProviderOpenAI,
+		Name:                "OpenAI",
+		DefaultLargeModelID: "large",
+		DefaultSmallModelID: "small",
+		Models:              []catwalk.Model{validModel("large"), validModel("small")},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *catwalk.Provider)
+		custom bool
+		want   string
+	}{
+		{name: "valid", modify: func(*catwalk.Provider) {}},
+		{
+			name:   "no id",
+			modify: func(p *catwalk.Provider) { p.ID = "" },
+			want:   "provider has no id",
+		},
+		{
+			name:   "unknown id",
+			modify: func(p *catwalk.Provider) { p.ID = "acme" },
+			want:   `provider "acme" is not a known provider`,
+		},
+		{
+			name:   "custom id",
+			modify: func(p *catwalk.Provider) { p.ID = "acme" },
+			custom: true,
+		},
+		{
+			name:   "no name",
+			modify: func(p *catwalk.Provider) { p.Name = "" },
+			want:   "has no name",
+		},
+		{
+			name: "no models",
+			modify: func(p *catwalk.Provider) {
+				p.Models = nil
+				p.DefaultLargeModelID, p.DefaultSmallModelID = "", ""
+			},
+			want: "has no models",
+		},
+		{
+			name:   "model without id",
+			modify: func(p *catwalk.Provider) { p.Models = append(p.Models, validModel("")) },
+			want:   "has no id",
+		},
+		{
+			name:   "duplicate model",
+			modify: func(p *catwalk.Provider) { p.Models = append(p.Models, validModel("large")) },
+			want:   `model "large" is defined more than once`,
+		},
+		{
+			name:   "unknown default large model",
+			modify: func(p *catwalk.Provider) { p.DefaultLargeModelID = "huge" },
+			want:   `default large model "huge" is not a model of the provider`,
+		},
+		{
+			name:   "unknown default small model",
+			modify: func(p *catwalk.Provider) { p.DefaultSmallModelID = "tiny" },
+			want:   `default small model "tiny" is not a model of the provider`,
+		},
+		{
+			name:   "invalid model",
+			modify: func(p *catwalk.Provider) { p.Models[0].ContextWindow = 0 },
+			want:   `model "large": context window must be positive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validProvider()
+			tt.modify(&p)
+			validate := Validate
+			if tt.custom {
+				validate = ValidateCustom
+			}
+			checkError(t, validate(p), tt.want)
+		})
+	}
+}
+
+func TestValidateModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(m *catwalk.Model)
+		want   string
+	}{
+		{name: "valid", modify: func(*catwalk.Model) {}},
+		{
+			name:   "no context window",
+			modify: func(m *catwalk.Model) { m.ContextWindow = 0 },
+			want:   "context window must be positive, got 0",
+		},
+		{
+			name:   "negative max tokens",
+			modify: func(m *catwalk.Model) { m.DefaultMaxTokens = -1 },
+			want:   "default max tokens must not be negative, got -1",
+		},
+		{
+			name:   "max tokens exceed context window",
+			modify: func(m *catwalk.Model) { m.DefaultMaxTokens = m.ContextWindow + 1 },
+			want:   "default max tokens 128001 exceed the context window 128000",
+		},
+		{
+			name:   "max tokens equal to context window",
+			modify: func(m *catwalk.Model) { m.DefaultMaxTokens = m.ContextWindow },
+		},
+		{
+			name:   "reasoning effort without reasoning",
+			modify: func(m *catwalk.Model) { m.HasReasoningEffort = true },
+			want:   "has reasoning efforts but cannot reason",
+		},
+		{
+			name: "reasoning effort with reasoning",
+			modify: func(m *catwalk.Model) {
+				m.CanReason = true
+				m.HasReasoningEffort = true
+			},
+		},
+		{
+			name:   "negative legacy price",
+			modify: func(m *catwalk.Model) { m.CostPer1MOut = -1 },
+			want:   "prices must not be negative",
+		},
+		{
+			name: "negative price",
+			modify: func(m *catwalk.Model) {
+				m.SetPricing(catwalk.Pricing{PerRequest: -0.01})
+			},
+			want: "prices must not be negative",
+		},
+		{
+			name: "legacy costs match the pricing",
+			modify: func(m *catwalk.Model) {
+				m.SetPricing(catwalk.Pricing{TokenPrices: catwalk.TokenPrices{Input: 1, Output: 2, CacheRead: 0.1}})
+			},
+		},
+		{
+			name: "legacy costs do not match the pricing",
+			modify: func(m *catwalk.Model) {
+				m.SetPricing(catwalk.Pricing{TokenPrices: catwalk.TokenPrices{Input: 1, Output: 2}})
+				m.CostPer1MOut = 3
+			},
+			want: "legacy costs",
+		},
+		{
+			name: "negative tier price",
+			modify: func(m *catwalk.Model) {
+				m.SetPricing(catwalk.Pricing{Tiers: []catwalk.PricingTier{
+					{AboveTokens: 200_000, TokenPrices: catwalk.TokenPrices{Input: -1}},
+				}})
+			},
+			want: "prices must not be negative",
+		},
+		{
+			name: "unsorted tiers",
+			modify: func(m *catwalk.Model) {
+				m.SetPricing(catwalk.Pricing{
+					TokenPrices: catwalk.TokenPrices{Input: 1, Output: 2},
+					Tiers: []catwalk.PricingTier{
+						{AboveTokens: 200_000, TokenPrices: catwalk.TokenPrices{Input: 3}},
+						{AboveTokens: 100_000, TokenPrices: catwalk.TokenPrices{Input: 2}},
+					},
+				})
+			},
+			want: "pricing tiers must be sorted by increasing size",
+		},
+		{
+			name: "sorted tiers",
+			modify: func(m *catwalk.Model) {
+				m.SetPricing(catwalk.Pricing{
+					TokenPrices: catwalk.TokenPrices{Input: 1, Output: 2},
+					Tiers: []catwalk.PricingTier{
+						{AboveTokens: 100_000, TokenPrices: catwalk.TokenPrices{Input: 2}},
+						{AboveTokens: 200_000, TokenPrices: catwalk.TokenPrices{Input: 3}},
+					},
+				})
+			},
+		},
+		{
+			name:   "upstream without provider",
+			modify: func(m *catwalk.Model) { m.Upstream = &catwalk.Route{Tag: "fp8"} },
+			want:   "upstream must have a provider",
+		},
+		{
+			name:   "upstream",
+			modify: func(m *catwalk.Model) { m.Upstream = &catwalk.Route{Provider: "groq"} },
+		},
+		{
+			name:   "route without provider",
+			modify: func(m *catwalk.Model) { m.Routes = []catwalk.Route{{Provider: "groq"}, {}} },
+			want:   "routes must have a provider",
+		},
+		{
+			name: "route with negative price",
+			modify: func(m *catwalk.Model) {
+				m.Routes = []catwalk.Route{{Provider: "groq", Pricing: &catwalk.Pricing{TokenPrices: catwalk.TokenPrices{Output: -1}}}}
+			},
+			want: "route groq: prices must not be negative",
+		},
+		{
+			name: "routes",
+			modify: func(m *catwalk.Model) {
+				m.Routes = []catwalk.Route{{Provider: "groq", Pricing: &catwalk.Pricing{TokenPrices: catwalk.TokenPrices{Output: 1}}}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := validModel("model")
+			tt.modify(&m)
+			checkError(t, ValidateModel(m), tt.want)
+		})
+	}
+}
+
+func TestValidateConfigs(t *testing.T) {
+	all, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range all {
+		if err := Validate(p); err != nil {
+			t.Errorf("provider %s: %v", p.ID, err)
+		}
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "valid", data: `{"id": "openai", "name": "OpenAI"}`},
+		{name: "unknown field", data: `{"id": "openai", "nmae": "OpenAI"}`, want: `unknown field "nmae"`},
+		{name: "trailing data", data: `{"id": "openai"} {}`, want: "trailing data after provider"},
+		{name: "invalid json", data: `{"id": `, want: "invalid provider config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeConfig([]byte(tt.data))
+			checkError(t, err, tt.want)
+		})
+	}
+}
+
+// checkError fails the test unless err contains want, or is nil when want
+// is empty.
+func checkError(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Errorf("expected an error containing %q", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Errorf("expected an error containing %q, got: %v", want, err)
+	}
+}
@@ -0,0 +1,60 @@
+// Package retry provides the helpers shared by the HTTP clients retrying
+// failed requests with exponential backoff.
+package retry
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retryable reports whether a request failing with the given status may
+// succeed when retried.
+func Retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// Backoff returns how long to wait before the given retry, starting at 1:
+// base, doubled on every retry. It saturates instead of overflowing.
+func Backoff(base time.Duration, retry int) time.Duration {
+	d := base
+	for range retry - 1 {
+		if d > math.MaxInt64/2 {
+			return math.MaxInt64
+		}
+		d *= 2
+	}
+	return d
+}
+
+// After parses a Retry-After header value, given either in seconds or as
+// an HTTP date.
+func After(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil && secs >= 0 {
+		if secs > math.MaxInt64/int64(time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// Sleep waits for d, or until ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-t.C:
+		return nil
+	}
+}
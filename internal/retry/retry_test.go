@@ -0,0 +1,53 @@
+package retry
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{retry: 1, want: time.Second},
+		{retry: 2, want: 2 * time.Second},
+		{retry: 4, want: 8 * time.Second},
+		{retry: 64, want: math.MaxInt64},
+		{retry: 1000, want: math.MaxInt64},
+	}
+	for _, tt := range tests {
+		if got := Backoff(time.Second, tt.retry); got != tt.want {
+			t.Errorf("Backoff(1s, %d) = %v, expected %v", tt.retry, got, tt.want)
+		}
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: ""},
+		{value: "soon"},
+		{value: "-1"},
+		{value: "0", ok: true},
+		{value: "120", want: 2 * time.Minute, ok: true},
+		{value: "99999999999999", want: math.MaxInt64, ok: true},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", ok: true},
+	}
+	for _, tt := range tests {
+		got, ok := After(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("After(%q) = %v, %v, expected %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got, ok := After(future); !ok || got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("After(%q) = %v, %v, expected about an hour", future, got, ok)
+	}
+}
@@ -4,11 +4,18 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/charmbracelet/catwalk/internal/gencmd"
 	"github.com/charmbracelet/catwalk/internal/providers"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -21,37 +28,270 @@ var counter = promauto.NewCounter(prometheus.CounterOpts{
 	Help:      "Total number of requests to the providers endpoint",
 })
 
-func providersHandler(w http.ResponseWriter, r *http.Request) {
+// server serves the provider catalog over HTTP.
+type server struct {
+	// catalog is swapped as a whole on reload, so every request is served
+	// from a single version of the catalog.
+	catalog atomic.Pointer[catalog]
+	// hideRetired hides the models retired at the time of each request.
+	hideRetired bool
+}
+
+// view returns the catalog to serve the request from. It writes an error
+// response and returns false when the catalog cannot be built.
+func (s *server) view(w http.ResponseWriter) (*catalog, bool) {
+	c := s.catalog.Load()
+	if !s.hideRetired {
+		return c, true
+	}
+	c, err := c.at(time.Now())
+	if err != nil {
+		log.Printf("Error hiding retired models: %v", err)
+		writeError(w, http.StatusInternalServerError, catwalk.ErrorCodeInternal, "Internal server error")
+		return nil, false
+	}
+	return c, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
-	if r.Method == http.MethodHead {
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, catwalk.ErrorResponse{Code: code, Message: message})
+}
+
+// methodNotAllowed returns a handler answering 405 Method Not Allowed with
+// the given allowed methods, in place of the plain text response of the mux.
+func methodNotAllowed(allowed ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed, catwalk.ErrorCodeMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *server) providersHandler(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.view(w)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(http.MethodGet, http.MethodHead)(w, r)
+		return
+	}
+
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, catwalk.ErrorCodeInvalidQuery, err.Error())
 		return
 	}
 
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if c.notModified(w, r) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.Method == http.MethodHead {
 		return
 	}
 
 	counter.Inc()
-	allProviders := providers.GetAll()
-	if err := json.NewEncoder(w).Encode(allProviders); err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if f.empty() {
+		_, _ = w.Write(c.data)
 		return
 	}
+	// Encoded before writing anything, so that a failure can still be
+	// reported with an error response.
+	data, err := json.Marshal(f.apply(c.providers))
+	if err != nil {
+		log.Printf("Error encoding providers: %v", err)
+		writeError(w, http.StatusInternalServerError, catwalk.ErrorCodeInternal, "Internal server error")
+		return
+	}
+	_, _ = w.Write(append(data, '\n'))
 }
 
-func main() {
+func (s *server) providerHandler(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.view(w)
+	if !ok {
+		return
+	}
+	id := catwalk.InferenceProvider(r.PathValue("id"))
+	p, ok := c.provider(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, catwalk.ErrorCodeProviderNotFound, fmt.Sprintf("provider %q not found", id))
+		return
+	}
+	if c.notModified(w, r) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *server) modelsHandler(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.view(w)
+	if !ok {
+		return
+	}
+	id := catwalk.InferenceProvider(r.PathValue("id"))
+	p, ok := c.provider(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, catwalk.ErrorCodeProviderNotFound, fmt.Sprintf("provider %q not found", id))
+		return
+	}
+	f, err := parseFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, catwalk.ErrorCodeInvalidQuery, err.Error())
+		return
+	}
+	if c.notModified(w, r) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	models := f.models(p.Models)
+	if models == nil {
+		models = []catwalk.Model{}
+	}
+	writeJSON(w, http.StatusOK, models)
+}
+
+func (s *server) modelHandler(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.view(w)
+	if !ok {
+		return
+	}
+	id := catwalk.InferenceProvider(r.PathValue("id"))
+	modelID := r.PathValue("modelID")
+	if _, ok := c.provider(id); !ok {
+		writeError(w, http.StatusNotFound, catwalk.ErrorCodeProviderNotFound, fmt.Sprintf("provider %q not found", id))
+		return
+	}
+	m, ok := c.model(id, modelID)
+	if !ok {
+		writeError(w, http.StatusNotFound, catwalk.ErrorCodeModelNotFound, fmt.Sprintf("model %q not found in provider %q", modelID, id))
+		return
+	}
+	if c.notModified(w, r) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// maxEstimateBody is the maximum size of a cost estimation request body.
+const maxEstimateBody = 64 << 10
+
+func (s *server) estimateHandler(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.view(w)
+	if !ok {
+		return
+	}
+	var req catwalk.EstimateRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEstimateBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, catwalk.ErrorCodeInvalidRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	u := req.Usage
+	if min(u.InputTokens, u.CacheReadTokens, u.CacheWriteTokens, u.AudioInputTokens, u.OutputTokens, u.ReasoningTokens, u.Images) < 0 {
+		writeError(w, http.StatusBadRequest, catwalk.ErrorCodeInvalidRequest, "usage must not be negative")
+		return
+	}
+
+	if _, ok := c.provider(req.Provider); !ok {
+		writeError(w, http.StatusNotFound, catwalk.ErrorCodeProviderNotFound, fmt.Sprintf("provider %q not found", req.Provider))
+		return
+	}
+	m, ok := c.model(req.Provider, req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, catwalk.ErrorCodeModelNotFound, fmt.Sprintf("model %q not found in provider %q", req.Model, req.Provider))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, catwalk.EstimateResponse{
+		Provider: req.Provider,
+		Model:    req.Model,
+		Usage:    req.Usage,
+		Cost:     m.EstimateCost(req.Usage),
+	})
+}
+
+// handler returns the routes of the server.
+func (s *server) handler() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/providers", providersHandler)
+	mux.HandleFunc("/providers", s.providersHandler)
+	mux.HandleFunc("GET /providers/{id}", s.providerHandler)
+	mux.HandleFunc("GET /providers/{id}/models", s.modelsHandler)
+	// Model IDs may contain slashes (e.g. "anthropic/claude-sonnet-4").
+	mux.HandleFunc("GET /providers/{id}/models/{modelID...}", s.modelHandler)
+	mux.HandleFunc("POST /estimate", s.estimateHandler)
+	// Other methods get a JSON error like every other error response.
+	get := methodNotAllowed(http.MethodGet, http.MethodHead)
+	mux.HandleFunc("/providers/{id}", get)
+	mux.HandleFunc("/providers/{id}/models", get)
+	mux.HandleFunc("/providers/{id}/models/{modelID...}", get)
+	mux.HandleFunc("/estimate", methodNotAllowed(http.MethodPost))
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("OK"))
 	})
 	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		commands := map[string]func([]string) error{
+			"validate": runValidate,
+			"gen":      gencmd.Run,
+		}
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
+	hideRetired := flag.Bool("hide-retired", false, "hide models past their retirement date")
+	configDir := flag.String("config-dir", os.Getenv(providers.ConfigDirEnv),
+		"directory of extra provider configs, overriding embedded providers with the same ID")
+	var overlays []string
+	flag.Func("overlay", "overlay `file` applied to the providers, may be repeated", func(path string) error {
+		overlays = append(overlays, path)
+		return nil
+	})
+	reloadInterval := flag.Duration("reload-interval", 5*time.Second,
+		"how often to check the config directory and overlays for changes, 0 disables reloading")
+	flag.Parse()
+
+	load := func() ([]catwalk.Provider, error) {
+		return providers.LoadWithDir(*configDir, overlays...) //nolint:wrapcheck
+	}
+
+	all, err := load()
+	if err != nil {
+		log.Fatal("Failed to load providers:", err)
+	}
+	c, err := newCatalog(all)
+	if err != nil {
+		log.Fatal("Failed to build catalog:", err)
+	}
+	s := &server{hideRetired: *hideRetired}
+	s.catalog.Store(c)
+	if *reloadInterval > 0 && (*configDir != "" || len(overlays) > 0) {
+		go s.watch(*configDir, overlays, *reloadInterval, load)
+	}
 
 	server := &http.Server{
 		Addr:         ":8080",
-		Handler:      mux,
+		Handler:      s.handler(),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
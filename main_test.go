@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func testProviders() []catwalk.Provider {
+	return []catwalk.Provider{
+		{
+			ID:                  catwalk.InferenceProviderAnthropic,
+			Name:                "Anthropic",
+			Type:                catwalk.TypeAnthropic,
+			DefaultLargeModelID: "claude-opus",
+			DefaultSmallModelID: "claude-haiku",
+			Models: []catwalk.Model{
+				{ID: "claude-opus", Name: "Claude Opus", ContextWindow: 200_000, CostPer1MIn: 15, CostPer1MOut: 75, CanReason: true, SupportsImages: true},
+				{ID: "claude-haiku", Name: "Claude Haiku", ContextWindow: 200_000, CostPer1MIn: 1, CostPer1MOut: 5},
+			},
+		},
+		{
+			ID:                  catwalk.InferenceProviderOpenRouter,
+			Name:                "OpenRouter",
+			Type:                catwalk.TypeOpenAI,
+			DefaultLargeModelID: "openai/gpt-5",
+			DefaultSmallModelID: "openai/gpt-5-mini",
+			Models: []catwalk.Model{
+				{ID: "openai/gpt-5", Name: "GPT-5", ContextWindow: 400_000, CostPer1MIn: 1.25, CostPer1MOut: 10, CanReason: true},
+				{ID: "openai/gpt-5-mini", Name: "GPT-5 Mini", ContextWindow: 128_000, CostPer1MIn: 0.25, CostPer1MOut: 2},
+			},
+		},
+	}
+}
+
+// newTestServer returns the routes of a server serving providers.
+func newTestServer(t *testing.T, providers []catwalk.Provider) http.Handler {
+	t.Helper()
+	c, err := newCatalog(providers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{}
+	s.catalog.Store(c)
+	return s.handler()
+}
+
+// serve sends a request to h and returns the recorded response.
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+		code   string
+		allow  string
+	}{
+		{
+			name:   "providers method",
+			method: http.MethodPost,
+			target: "/providers",
+			status: http.StatusMethodNotAllowed,
+			code:   catwalk.ErrorCodeMethodNotAllowed,
+			allow:  "GET, HEAD",
+		},
+		{
+			name:   "provider method",
+			method: http.MethodDelete,
+			target: "/providers/anthropic",
+			status: http.StatusMethodNotAllowed,
+			code:   catwalk.ErrorCodeMethodNotAllowed,
+			allow:  "GET, HEAD",
+		},
+		{
+			name:   "models method",
+			method: http.MethodPut,
+			target: "/providers/anthropic/models",
+			status: http.StatusMethodNotAllowed,
+			code:   catwalk.ErrorCodeMethodNotAllowed,
+			allow:  "GET, HEAD",
+		},
+		{
+			name:   "model method",
+			method: http.MethodPatch,
+			target: "/providers/openrouter/models/openai/gpt-5",
+			status: http.StatusMethodNotAllowed,
+			code:   catwalk.ErrorCodeMethodNotAllowed,
+			allow:  "GET, HEAD",
+		},
+		{
+			name:   "estimate method",
+			method: http.MethodGet,
+			target: "/estimate",
+			status: http.StatusMethodNotAllowed,
+			code:   catwalk.ErrorCodeMethodNotAllowed,
+			allow:  "POST",
+		},
+		{
+			name:   "unknown provider",
+			method: http.MethodGet,
+			target: "/providers/acme",
+			status: http.StatusNotFound,
+			code:   catwalk.ErrorCodeProviderNotFound,
+		},
+		{
+			name:   "unknown model",
+			method: http.MethodGet,
+			target: "/providers/openrouter/models/openai/gpt-6",
+			status: http.StatusNotFound,
+			code:   catwalk.ErrorCodeModelNotFound,
+		},
+	}
+	h := newTestServer(t, testProviders())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, tt.method, tt.target, "")
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow = %q, want %q", got, tt.allow)
+			}
+			checkErrorResponse(t, rec, tt.code)
+		})
+	}
+}
+
+func TestViewError(t *testing.T) {
+	// A provider that cannot be encoded fails the view hiding the retired
+	// models.
+	c, err := newCatalog(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.providers = []catwalk.Provider{{ID: "broken", Models: []catwalk.Model{{CostPer1MIn: math.NaN()}}}}
+	s := &server{hideRetired: true}
+	s.catalog.Store(c)
+
+	rec := serve(s.handler(), http.MethodGet, "/providers", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	checkErrorResponse(t, rec, catwalk.ErrorCodeInternal)
+}
+
+// checkErrorResponse fails the test unless rec holds a JSON error response
+// with the given code.
+func checkErrorResponse(t *testing.T, rec *httptest.ResponseRecorder, code string) {
+	t.Helper()
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var er catwalk.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &er); err != nil {
+		t.Fatalf("invalid error response %q: %v", rec.Body, err)
+	}
+	if er.Code != code {
+		t.Errorf("code = %q, want %q", er.Code, code)
+	}
+	if er.Message == "" {
+		t.Error("error response has no message")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	providers := testProviders()
+	providers[1].Models[0].SetPricing(catwalk.Pricing{
+		TokenPrices: catwalk.TokenPrices{Input: 1.25, Output: 10},
+		Tiers: []catwalk.PricingTier{
+			{AboveTokens: 200_000, TokenPrices: catwalk.TokenPrices{Input: 2.5, Output: 15}},
+		},
+	})
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+		// total is the estimated cost of a successful request.
+		total float64
+	}{
+		{
+			name:   "base tier",
+			body:   `{"provider": "openrouter", "model": "openai/gpt-5", "usage": {"input_tokens": 200000, "output_tokens": 100000}}`,
+			status: http.StatusOK,
+			total:  0.25 + 1,
+		},
+		{
+			name:   "200k tier",
+			body:   `{"provider": "openrouter", "model": "openai/gpt-5", "usage": {"input_tokens": 200001, "output_tokens": 100000}}`,
+			status: http.StatusOK,
+			total:  200_001*2.5/1e6 + 1.5,
+		},
+		{
+			name:   "legacy costs",
+			body:   `{"provider": "anthropic", "model": "claude-haiku", "usage": {"input_tokens": 1000000, "output_tokens": 1000000}}`,
+			status: http.StatusOK,
+			total:  1 + 5,
+		},
+		{
+			name:   "invalid json",
+			body:   `{"provider": "openrouter",`,
+			status: http.StatusBadRequest,
+			code:   catwalk.ErrorCodeInvalidRequest,
+		},
+		{
+			name:   "unknown field",
+			body:   `{"provider": "openrouter", "model": "openai/gpt-5", "tokens": 12}`,
+			status: http.StatusBadRequest,
+			code:   catwalk.ErrorCodeInvalidRequest,
+		},
+		{
+			name:   "wrong type",
+			body:   `{"provider": "openrouter", "model": "openai/gpt-5", "usage": {"input_tokens": "many"}}`,
+			status: http.StatusBadRequest,
+			code:   catwalk.ErrorCodeInvalidRequest,
+		},
+		{
+			name:   "negative usage",
+			body:   `{"provider": "openrouter", "model": "openai/gpt-5", "usage": {"input_tokens": 10, "output_tokens": -1}}`,
+			status: http.StatusBadRequest,
+			code:   catwalk.ErrorCodeInvalidRequest,
+		},
+		{
+			name:   "too large",
+			body:   `{"provider": "` + strings.Repeat("x", maxEstimateBody) + `"}`,
+			status: http.StatusBadRequest,
+			code:   catwalk.ErrorCodeInvalidRequest,
+		},
+		{
+			name:   "unknown provider",
+			body:   `{"provider": "acme", "model": "openai/gpt-5"}`,
+			status: http.StatusNotFound,
+			code:   catwalk.ErrorCodeProviderNotFound,
+		},
+		{
+			name:   "unknown model",
+			body:   `{"provider": "openrouter", "model": "openai/gpt-6"}`,
+			status: http.StatusNotFound,
+			code:   catwalk.ErrorCodeModelNotFound,
+		},
+	}
+	h := newTestServer(t, providers)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodPost, "/estimate", tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.code != "" {
+				checkErrorResponse(t, rec, tt.code)
+				return
+			}
+			var resp catwalk.EstimateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(resp.Cost.Total-tt.total) > 1e-9 {
+				t.Errorf("total cost = %g, want %g", resp.Cost.Total, tt.total)
+			}
+		})
+	}
+}
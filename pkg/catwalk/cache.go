@@ -0,0 +1,93 @@
+package catwalk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheEntry is the on-disk representation of the last successful
+// response of the service.
+type cacheEntry struct {
+	URL       string     `json:"url"`
+	ETag      string     `json:"etag,omitempty"`
+	FetchedAt time.Time  `json:"fetched_at"`
+	Providers []Provider `json:"providers"`
+}
+
+// diskCache persists the catalog fetched from a given service URL.
+type diskCache struct {
+	path string
+	ttl  time.Duration
+}
+
+// DefaultCacheDir returns the directory used by [WithCache] when none is
+// given, under the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "catwalk"), nil
+}
+
+func newDiskCache(dir, baseURL string, ttl time.Duration) *diskCache {
+	// Key the file by URL so clients of different services do not share a
+	// catalog.
+	sum := sha256.Sum256([]byte(baseURL))
+	name := "providers-" + hex.EncodeToString(sum[:4]) + ".json"
+	return &diskCache{
+		path: filepath.Join(dir, name),
+		ttl:  ttl,
+	}
+}
+
+// load reads the cached entry, if any.
+func (d *diskCache) load() (*cacheEntry, error) {
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode cache: %w", err)
+	}
+	return &entry, nil
+}
+
+// store atomically replaces the cached entry.
+func (d *diskCache) store(entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(d.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), ".providers-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.path); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// fresh reports whether a catalog fetched at the given time can be served
+// without contacting the service.
+func (d *diskCache) fresh(fetchedAt time.Time) bool {
+	return time.Since(fetchedAt) < d.ttl
+}
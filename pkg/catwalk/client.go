@@ -1,60 +1,292 @@
 package catwalk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/catwalk/internal/retry"
 )
 
-const defaultURL = "http://localhost:8080"
+const (
+	defaultURL       = "http://localhost:8080"
+	defaultTimeout   = 30 * time.Second
+	defaultUserAgent = "Catwalk-Client/1.0"
+)
 
 // Client represents a client for the catwalk service.
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	headers    http.Header
+	retry      RetryPolicy
+	fallback   func() []Provider
+	cacheDir   string
+	cacheTTL   time.Duration
+	cache      *diskCache
+
+	// mu guards the last successful response, which is reused when the
+	// service answers 304 Not Modified, is fresh enough to skip the request
+	// entirely, or the service cannot be reached.
+	mu        sync.Mutex
+	etag      string
+	fetchedAt time.Time
+	providers []Provider
+	// loaded reports whether the on-disk cache was read already.
+	loaded bool
+
+	revalidating atomic.Bool
 }
 
-// New creates a new client instance
-// Uses CATWALK_URL environment variable or falls back to localhost:8080.
-func New() *Client {
+// New creates a new client instance configured with the given options.
+// Unless [WithBaseURL] is given, it uses the CATWALK_URL environment
+// variable or falls back to localhost:8080.
+func New(opts ...Option) *Client {
 	baseURL := os.Getenv("CATWALK_URL")
 	if baseURL == "" {
 		baseURL = defaultURL
 	}
 
-	return &Client{
+	c := &Client{
 		baseURL:    baseURL,
 		httpClient: &http.Client{},
+		timeout:    defaultTimeout,
+		userAgent:  defaultUserAgent,
+		headers:    http.Header{},
+		retry:      RetryPolicy{MaxAttempts: 1},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.cacheTTL > 0 {
+		if c.cacheDir == "" {
+			c.cacheDir, _ = DefaultCacheDir()
+		}
+		if c.cacheDir != "" {
+			c.cache = newDiskCache(c.cacheDir, c.baseURL, c.cacheTTL)
+		}
+	}
+	return c
 }
 
 // NewWithURL creates a new client with a specific URL.
-func NewWithURL(url string) *Client {
-	return &Client{
-		baseURL:    url,
-		httpClient: &http.Client{},
-	}
+func NewWithURL(url string, opts ...Option) *Client {
+	return New(append([]Option{WithBaseURL(url)}, opts...)...)
 }
 
 // GetProviders retrieves all available providers from the service.
 func (c *Client) GetProviders() ([]Provider, error) {
+	return c.GetProvidersContext(context.Background())
+}
+
+// GetProvidersContext retrieves all available providers from the service.
+//
+// The client remembers the ETag of the last successful response and sends
+// it along with subsequent requests, reusing the previous result when the
+// service reports that the catalog has not changed.
+func (c *Client) GetProvidersContext(ctx context.Context) ([]Provider, error) {
+	providers, _, err := c.GetProvidersWithSource(ctx)
+	return providers, err
+}
+
+// GetProvidersWithSource is like [Client.GetProvidersContext], but also
+// reports where the providers came from.
+//
+// When the client was created with [WithCache], a catalog fetched less than
+// the cache TTL ago is returned with [SourceCache] without contacting the
+// service. An older one is returned as well, while being revalidated in the
+// background.
+//
+// When the client was created with [WithCache] or [WithFallback] and the
+// service cannot be reached or answers with an error, the last providers
+// successfully fetched are returned with [SourceCache]. Failing that, the
+// fallback function is used and [SourceFallback] is reported. The error is
+// only returned when no source could provide a catalog, or when ctx is done,
+// in which case no source is used.
+func (c *Client) GetProvidersWithSource(ctx context.Context) ([]Provider, Source, error) {
+	// A caller giving up is not a failure of the service, there is nothing
+	// to fall back from.
+	if err := ctx.Err(); err != nil {
+		return nil, "", err //nolint:wrapcheck
+	}
+	if c.cache != nil {
+		c.loadCache()
+		c.mu.Lock()
+		cached, fetchedAt := c.providers, c.fetchedAt
+		c.mu.Unlock()
+		if cached != nil {
+			if !c.cache.fresh(fetchedAt) {
+				c.revalidate()
+			}
+			return slices.Clone(cached), SourceCache, nil
+		}
+	}
+
+	providers, err := c.fetch(ctx)
+	if err == nil {
+		return providers, SourceRemote, nil
+	}
+	if c.fallback == nil && c.cache == nil || ctx.Err() != nil {
+		return nil, "", err
+	}
+
+	c.mu.Lock()
+	cached := c.providers
+	c.mu.Unlock()
+	if cached != nil {
+		return slices.Clone(cached), SourceCache, nil
+	}
+	if c.fallback != nil {
+		if providers := c.fallback(); len(providers) > 0 {
+			return providers, SourceFallback, nil
+		}
+	}
+	return nil, "", err
+}
+
+// loadCache loads the on-disk cache into memory, once.
+func (c *Client) loadCache() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	entry, err := c.cache.load()
+	if err != nil || entry.URL != c.baseURL || entry.Providers == nil {
+		return
+	}
+	c.etag = entry.ETag
+	c.fetchedAt = entry.FetchedAt
+	c.providers = entry.Providers
+}
+
+// revalidate refreshes the cached catalog in the background, unless a
+// refresh is already in progress.
+func (c *Client) revalidate() {
+	if !c.revalidating.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer c.revalidating.Store(false)
+		_, _ = c.fetch(context.Background())
+	}()
+}
+
+func (c *Client) fetch(ctx context.Context) ([]Provider, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
 	url := fmt.Sprintf("%s/providers", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.mu.Lock()
+	etag, cached := c.etag, c.providers
+	c.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 
-	resp, err := c.httpClient.Get(url) //nolint:noctx
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close() //nolint:errcheck
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		c.update(etag, cached)
+		return slices.Clone(cached), nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newHTTPError(resp)
 	}
 
 	var providers []Provider
 	if err := json.NewDecoder(resp.Body).Decode(&providers); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrDecode, err)
 	}
 
-	return providers, nil
+	c.update(resp.Header.Get("ETag"), providers)
+	return slices.Clone(providers), nil
+}
+
+// update records a successful response, persisting it when the on-disk
+// cache is enabled.
+func (c *Client) update(etag string, providers []Provider) {
+	fetchedAt := time.Now()
+	c.mu.Lock()
+	c.etag = etag
+	c.fetchedAt = fetchedAt
+	c.providers = providers
+	c.mu.Unlock()
+
+	if c.cache != nil {
+		// The cache is only an optimization: failing to write it must not
+		// fail the call.
+		_ = c.cache.store(cacheEntry{
+			URL:       c.baseURL,
+			ETag:      etag,
+			FetchedAt: fetchedAt,
+			Providers: providers,
+		})
+	}
+}
+
+// do sends the request, retrying according to the client's retry policy.
+// The last response is returned as is once attempts are exhausted.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	attempts := max(c.retry.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		last := attempt >= attempts
+		if err != nil {
+			if req.Context().Err() != nil {
+				return nil, fmt.Errorf("failed to make request: %w", err)
+			}
+			if last {
+				return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+			}
+			if err := retry.Sleep(req.Context(), c.retry.delay(attempt)); err != nil {
+				return nil, fmt.Errorf("failed to make request: %w", err)
+			}
+			continue
+		}
+
+		if last || !retry.Retryable(resp.StatusCode) {
+			return resp, nil
+		}
+
+		delay := c.retry.delay(attempt)
+		if after, ok := retry.After(resp.Header.Get("Retry-After")); ok {
+			delay = c.retry.clamp(after)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if err := retry.Sleep(req.Context(), delay); err != nil {
+			return nil, fmt.Errorf("failed to make request: %w", err)
+		}
+	}
 }
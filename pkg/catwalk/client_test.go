@@ -0,0 +1,472 @@
+package catwalk_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+var testProviders = []catwalk.Provider{
+	{ID: catwalk.InferenceProviderOpenAI, Name: "OpenAI"},
+	{ID: catwalk.InferenceProviderAnthropic, Name: "Anthropic"},
+}
+
+// server is a stand-in of the catwalk service answering each request with
+// the next of its responses, and counting the requests.
+type server struct {
+	*httptest.Server
+	requests atomic.Int32
+}
+
+// response is an answer of the stand-in service. A zero status answers 200
+// with the test providers.
+type response struct {
+	status int
+	header http.Header
+	body   string
+	// check is called with the request, when set.
+	check func(t *testing.T, r *http.Request)
+}
+
+func newServer(t *testing.T, responses ...response) *server {
+	t.Helper()
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(s.requests.Add(1))
+		if n > len(responses) {
+			t.Errorf("unexpected request %d", n)
+			w.WriteHeader(http.StatusTeapot)
+			return
+		}
+		resp := responses[n-1]
+		if resp.check != nil {
+			resp.check(t, r)
+		}
+		for name, values := range resp.header {
+			w.Header()[name] = values
+		}
+		if resp.status == 0 {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(testProviders)
+			return
+		}
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func checkProviders(t *testing.T, got []catwalk.Provider) {
+	t.Helper()
+	if len(got) != len(testProviders) {
+		t.Fatalf("got %d providers, want %d", len(got), len(testProviders))
+	}
+	for i := range got {
+		if got[i].ID != testProviders[i].ID {
+			t.Errorf("provider %d is %q, want %q", i, got[i].ID, testProviders[i].ID)
+		}
+	}
+}
+
+func TestETag(t *testing.T) {
+	const etag = `"v1"`
+	srv := newServer(t,
+		response{header: http.Header{"Etag": {etag}}, check: func(t *testing.T, r *http.Request) {
+			if inm := r.Header.Get("If-None-Match"); inm != "" {
+				t.Errorf("first request sent If-None-Match %q", inm)
+			}
+		}},
+		response{status: http.StatusNotModified, check: func(t *testing.T, r *http.Request) {
+			if inm := r.Header.Get("If-None-Match"); inm != etag {
+				t.Errorf("If-None-Match = %q, want %q", inm, etag)
+			}
+		}},
+		response{header: http.Header{"Etag": {`"v2"`}}},
+		response{status: http.StatusNotModified, check: func(t *testing.T, r *http.Request) {
+			if inm := r.Header.Get("If-None-Match"); inm != `"v2"` {
+				t.Errorf("If-None-Match = %q, want the latest ETag", inm)
+			}
+		}},
+	)
+	c := catwalk.NewWithURL(srv.URL)
+	for range 4 {
+		providers, source, err := c.GetProvidersWithSource(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if source != catwalk.SourceRemote {
+			t.Errorf("source = %q, want %q", source, catwalk.SourceRemote)
+		}
+		checkProviders(t, providers)
+	}
+}
+
+func TestNotModifiedWithoutCatalog(t *testing.T) {
+	// A 304 is only expected in answer to a conditional request. Without a
+	// previous catalog to reuse, it is an error.
+	srv := newServer(t, response{status: http.StatusNotModified})
+	if _, err := catwalk.NewWithURL(srv.URL).GetProviders(); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	// Fast enough for the tests, a Retry-After of an hour is clamped to
+	// MaxDelay.
+	policy := catwalk.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	unavailable := response{status: http.StatusServiceUnavailable}
+	tests := []struct {
+		name      string
+		policy    *catwalk.RetryPolicy
+		responses []response
+		status    int
+	}{
+		{name: "success", policy: &policy, responses: []response{{}}},
+		{name: "no retries by default", responses: []response{unavailable}, status: http.StatusServiceUnavailable},
+		{name: "retried server error", policy: &policy, responses: []response{unavailable, unavailable, {}}},
+		{
+			name:      "attempts exhausted",
+			policy:    &policy,
+			responses: []response{unavailable, unavailable, unavailable},
+			status:    http.StatusServiceUnavailable,
+		},
+		{
+			name:      "client error",
+			policy:    &policy,
+			responses: []response{{status: http.StatusUnauthorized}},
+			status:    http.StatusUnauthorized,
+		},
+		{
+			name:   "retry after",
+			policy: &policy,
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"3600"}}},
+				{},
+			},
+		},
+		{
+			name:   "retry after date",
+			policy: &policy,
+			responses: []response{
+				{status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}},
+				{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.responses...)
+			// Fail rather than hang when a delay is not clamped.
+			opts := []catwalk.Option{catwalk.WithTimeout(5 * time.Second)}
+			if tt.policy != nil {
+				opts = append(opts, catwalk.WithRetry(*tt.policy))
+			}
+			start := time.Now()
+			providers, err := catwalk.NewWithURL(srv.URL, opts...).GetProviders()
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("took %s, the delays are not clamped", elapsed)
+			}
+			if got := int(srv.requests.Load()); got != len(tt.responses) {
+				t.Errorf("sent %d requests, want %d", got, len(tt.responses))
+			}
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkProviders(t, providers)
+				return
+			}
+			var httpErr *catwalk.HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("expected an HTTPError, got: %v", err)
+			}
+			if httpErr.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", httpErr.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	srv := newServer(t, response{status: http.StatusServiceUnavailable})
+	c := catwalk.NewWithURL(srv.URL, catwalk.WithRetry(catwalk.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Hour}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.GetProvidersContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error while waiting to retry, got: %v", err)
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	srv := newServer(t, response{check: func(t *testing.T, r *http.Request) {
+		want := map[string]string{
+			"User-Agent":    "test/1.0",
+			"Authorization": "Bearer secret",
+			"X-Team":        "catwalk",
+		}
+		for name, value := range want {
+			if got := r.Header.Get(name); got != value {
+				t.Errorf("%s = %q, want %q", name, got, value)
+			}
+		}
+	}})
+	c := catwalk.New(
+		catwalk.WithBaseURL(srv.URL),
+		catwalk.WithUserAgent("test/1.0"),
+		catwalk.WithBearerToken("secret"),
+		catwalk.WithHeader("X-Team", "catwalk"),
+	)
+	if _, err := c.GetProviders(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	embedded := []catwalk.Provider{{ID: catwalk.InferenceProviderOpenAI, Name: "Embedded"}}
+	failure := response{status: http.StatusInternalServerError}
+	tests := []struct {
+		name      string
+		fallback  func() []catwalk.Provider
+		responses []response
+		// source is the source of the last call, none when it fails.
+		source catwalk.Source
+		// first is the name of the first provider of the last call.
+		first string
+	}{
+		{
+			name:      "remote",
+			fallback:  func() []catwalk.Provider { return embedded },
+			responses: []response{{}},
+			source:    catwalk.SourceRemote,
+			first:     "OpenAI",
+		},
+		{name: "no fallback", responses: []response{failure}},
+		{
+			name:      "fallback",
+			fallback:  func() []catwalk.Provider { return embedded },
+			responses: []response{failure},
+			source:    catwalk.SourceFallback,
+			first:     "Embedded",
+		},
+		{name: "empty fallback", fallback: func() []catwalk.Provider { return nil }, responses: []response{failure}},
+		{
+			name:      "last known good",
+			fallback:  func() []catwalk.Provider { return embedded },
+			responses: []response{{}, failure},
+			source:    catwalk.SourceCache,
+			first:     "OpenAI",
+		},
+		{
+			name:      "client error",
+			fallback:  func() []catwalk.Provider { return embedded },
+			responses: []response{{status: http.StatusNotFound}},
+			source:    catwalk.SourceFallback,
+			first:     "Embedded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.responses...)
+			var opts []catwalk.Option
+			if tt.fallback != nil {
+				opts = append(opts, catwalk.WithFallback(tt.fallback))
+			}
+			c := catwalk.NewWithURL(srv.URL, opts...)
+			var (
+				providers []catwalk.Provider
+				source    catwalk.Source
+				err       error
+			)
+			for range tt.responses {
+				providers, source, err = c.GetProvidersWithSource(context.Background())
+			}
+			if source != tt.source {
+				t.Errorf("source = %q, want %q", source, tt.source)
+			}
+			if tt.source == "" {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(providers) == 0 || providers[0].Name != tt.first {
+				t.Errorf("got providers %v, want the first one named %q", providers, tt.first)
+			}
+		})
+	}
+}
+
+func TestFallbackUnreachable(t *testing.T) {
+	srv := newServer(t)
+	srv.Close()
+	embedded := []catwalk.Provider{{ID: catwalk.InferenceProviderOpenAI, Name: "Embedded"}}
+	c := catwalk.NewWithURL(srv.URL, catwalk.WithFallback(func() []catwalk.Provider { return embedded }))
+	providers, source, err := c.GetProvidersWithSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != catwalk.SourceFallback || len(providers) != 1 {
+		t.Errorf("got %d providers from %q, want the fallback", len(providers), source)
+	}
+}
+
+func TestFallbackCanceled(t *testing.T) {
+	// A caller giving up does not fall back, and the service is not
+	// contacted.
+	srv := newServer(t)
+	c := catwalk.NewWithURL(srv.URL, catwalk.WithFallback(func() []catwalk.Provider { return testProviders }))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, source, err := c.GetProvidersWithSource(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got: %v", err)
+	}
+	if source != "" {
+		t.Errorf("source = %q, want none", source)
+	}
+}
+
+// cachedETag returns the ETag of the catalog cached in dir.
+func cachedETag(t *testing.T, dir string) string {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(dir, "providers-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("got cache files %v, want one", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry struct {
+		ETag string `json:"etag"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	return entry.ETag
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	srv := newServer(t, response{header: http.Header{"Etag": {`"v1"`}}})
+	_, source, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, time.Hour)).GetProvidersWithSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != catwalk.SourceRemote {
+		t.Errorf("first call: source = %q, want %q", source, catwalk.SourceRemote)
+	}
+	if etag := cachedETag(t, dir); etag != `"v1"` {
+		t.Errorf("cached ETag = %q, want %q", etag, `"v1"`)
+	}
+
+	// A new client serves the fresh catalog from disk, without contacting
+	// the service.
+	providers, source, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, time.Hour)).GetProvidersWithSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != catwalk.SourceCache {
+		t.Errorf("second call: source = %q, want %q", source, catwalk.SourceCache)
+	}
+	checkProviders(t, providers)
+	if n := srv.requests.Load(); n != 1 {
+		t.Errorf("sent %d requests, want 1", n)
+	}
+
+	// The cache is keyed by URL: another service does not share it.
+	other := newServer(t, response{})
+	_, source, err = catwalk.NewWithURL(other.URL, catwalk.WithCache(dir, time.Hour)).GetProvidersWithSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != catwalk.SourceRemote {
+		t.Errorf("other service: source = %q, want %q", source, catwalk.SourceRemote)
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	dir := t.TempDir()
+	srv := newServer(t,
+		response{header: http.Header{"Etag": {`"v1"`}}},
+		response{header: http.Header{"Etag": {`"v2"`}}, check: func(t *testing.T, r *http.Request) {
+			if inm := r.Header.Get("If-None-Match"); inm != `"v1"` {
+				t.Errorf("revalidation sent If-None-Match %q, want the cached ETag", inm)
+			}
+		}},
+	)
+	const ttl = 10 * time.Millisecond
+	if _, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, ttl)).GetProviders(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * ttl)
+
+	// The stale catalog is served right away, and revalidated in the
+	// background.
+	providers, source, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, ttl)).GetProvidersWithSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != catwalk.SourceCache {
+		t.Errorf("source = %q, want %q", source, catwalk.SourceCache)
+	}
+	checkProviders(t, providers)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cachedETag(t, dir) != `"v2"` {
+		if time.Now().After(deadline) {
+			t.Fatal("the stale catalog was not revalidated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := srv.requests.Load(); n != 2 {
+		t.Errorf("sent %d requests, want 2", n)
+	}
+}
+
+func TestCacheInvalid(t *testing.T) {
+	// A corrupt cache file is ignored.
+	dir := t.TempDir()
+	srv := newServer(t, response{}, response{})
+	if _, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, time.Hour)).GetProviders(); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "providers-*.json"))
+	for _, file := range files {
+		if err := os.WriteFile(file, []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, source, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, time.Hour)).GetProvidersWithSource(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != catwalk.SourceRemote {
+		t.Errorf("source = %q, want %q", source, catwalk.SourceRemote)
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	dir := t.TempDir()
+	srv := newServer(t, response{})
+	if _, err := catwalk.NewWithURL(srv.URL, catwalk.WithCache(dir, 0)).GetProviders(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("a disabled cache wrote %d files", len(entries))
+	}
+}
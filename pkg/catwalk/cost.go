@@ -0,0 +1,87 @@
+package catwalk
+
+// Usage describes the tokens consumed by a single request.
+type Usage struct {
+	// InputTokens are the input tokens neither read from nor written to
+	// the prompt cache.
+	InputTokens      int64 `json:"input_tokens"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	AudioInputTokens int64 `json:"audio_input_tokens,omitempty"`
+	// OutputTokens are the output tokens, excluding reasoning tokens.
+	OutputTokens    int64 `json:"output_tokens"`
+	ReasoningTokens int64 `json:"reasoning_tokens,omitempty"`
+	Images          int64 `json:"images,omitempty"`
+}
+
+// PromptTokens returns the size of the prompt, which selects the pricing
+// tier of the request.
+func (u Usage) PromptTokens() int64 {
+	return u.InputTokens + u.CacheReadTokens + u.CacheWriteTokens + u.AudioInputTokens
+}
+
+// Cost is the cost of a request, in USD.
+type Cost struct {
+	Input      float64 `json:"input"`
+	CacheRead  float64 `json:"cache_read"`
+	CacheWrite float64 `json:"cache_write"`
+	AudioInput float64 `json:"audio_input"`
+	Output     float64 `json:"output"`
+	Reasoning  float64 `json:"reasoning"`
+	Images     float64 `json:"images"`
+	Request    float64 `json:"request"`
+	Total      float64 `json:"total"`
+}
+
+// EstimateCost returns the cost of a request with the given usage, applying
+// the pricing tier matching the size of its prompt. Reasoning tokens are
+// billed as output tokens unless the model has a reasoning price, and audio
+// input tokens as input tokens unless it has an audio price.
+func (m Model) EstimateCost(u Usage) Cost {
+	pricing := m.Prices()
+	prices := pricing.At(u.PromptTokens())
+
+	reasoningPrice := prices.Reasoning
+	if reasoningPrice == 0 {
+		reasoningPrice = prices.Output
+	}
+	audioPrice := pricing.AudioInput
+	if audioPrice == 0 {
+		audioPrice = prices.Input
+	}
+
+	c := Cost{
+		Input:      perMillion(u.InputTokens, prices.Input),
+		CacheRead:  perMillion(u.CacheReadTokens, prices.CacheRead),
+		CacheWrite: perMillion(u.CacheWriteTokens, prices.CacheWrite),
+		AudioInput: perMillion(u.AudioInputTokens, audioPrice),
+		Output:     perMillion(u.OutputTokens, prices.Output),
+		Reasoning:  perMillion(u.ReasoningTokens, reasoningPrice),
+		Images:     float64(u.Images) * pricing.PerImage,
+		Request:    pricing.PerRequest,
+	}
+	c.Total = c.Input + c.CacheRead + c.CacheWrite + c.AudioInput +
+		c.Output + c.Reasoning + c.Images + c.Request
+	return c
+}
+
+func perMillion(tokens int64, price float64) float64 {
+	return float64(tokens) * price / 1_000_000
+}
+
+// EstimateRequest is the body of a request to the cost estimation endpoint
+// of the service.
+type EstimateRequest struct {
+	Provider InferenceProvider `json:"provider"`
+	Model    string            `json:"model"`
+	Usage    Usage             `json:"usage"`
+}
+
+// EstimateResponse is the body of a response of the cost estimation
+// endpoint of the service.
+type EstimateResponse struct {
+	Provider InferenceProvider `json:"provider"`
+	Model    string            `json:"model"`
+	Usage    Usage             `json:"usage"`
+	Cost     Cost              `json:"cost"`
+}
@@ -0,0 +1,100 @@
+package catwalk_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tiered := catwalk.Model{}
+	tiered.SetPricing(tieredPricing)
+	tests := []struct {
+		name  string
+		model catwalk.Model
+		usage catwalk.Usage
+		want  catwalk.Cost
+	}{
+		{name: "no usage", model: tiered},
+		{
+			// A prompt of exactly 200k tokens stays in the base tier.
+			name:  "base tier",
+			model: tiered,
+			usage: catwalk.Usage{InputTokens: 100_000, CacheReadTokens: 100_000, OutputTokens: 10_000, ReasoningTokens: 1_000},
+			want:  catwalk.Cost{Input: 0.125, CacheRead: 0.031, Output: 0.1, Reasoning: 0.012},
+		},
+		{
+			// The cache counts towards the prompt size selecting the tier.
+			name:  "long tier",
+			model: tiered,
+			usage: catwalk.Usage{InputTokens: 100_000, CacheReadTokens: 100_000, CacheWriteTokens: 1, OutputTokens: 10_000},
+			want:  catwalk.Cost{Input: 0.25, CacheRead: 0.031, CacheWrite: 0.0000015, Output: 0.15},
+		},
+		{
+			name: "legacy costs",
+			model: catwalk.Model{
+				CostPer1MIn: 3, CostPer1MOut: 15, CostPer1MInCached: 3.75, CostPer1MOutCached: 0.3,
+			},
+			usage: catwalk.Usage{InputTokens: 1_000_000, CacheReadTokens: 1_000_000, CacheWriteTokens: 1_000_000, OutputTokens: 1_000_000},
+			want:  catwalk.Cost{Input: 3, CacheRead: 0.3, CacheWrite: 3.75, Output: 15},
+		},
+		{
+			name:  "reasoning billed as output",
+			model: catwalk.Model{CostPer1MIn: 1, CostPer1MOut: 4},
+			usage: catwalk.Usage{OutputTokens: 1_000_000, ReasoningTokens: 500_000},
+			want:  catwalk.Cost{Output: 4, Reasoning: 2},
+		},
+		{
+			name:  "audio billed as input",
+			model: catwalk.Model{CostPer1MIn: 1, CostPer1MOut: 4},
+			usage: catwalk.Usage{InputTokens: 1_000_000, AudioInputTokens: 1_000_000},
+			want:  catwalk.Cost{Input: 1, AudioInput: 1},
+		},
+		{
+			name: "audio price",
+			model: catwalk.Model{Pricing: &catwalk.Pricing{
+				TokenPrices: catwalk.TokenPrices{Input: 1, Output: 4},
+				AudioInput:  8,
+			}},
+			usage: catwalk.Usage{InputTokens: 1_000_000, AudioInputTokens: 1_000_000},
+			want:  catwalk.Cost{Input: 1, AudioInput: 8},
+		},
+		{
+			name: "request and image fees",
+			model: catwalk.Model{Pricing: &catwalk.Pricing{
+				TokenPrices: catwalk.TokenPrices{Input: 1, Output: 4},
+				PerRequest:  0.01,
+				PerImage:    0.002,
+			}},
+			usage: catwalk.Usage{Images: 3},
+			want:  catwalk.Cost{Images: 0.006, Request: 0.01},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Total = tt.want.Input + tt.want.CacheRead + tt.want.CacheWrite + tt.want.AudioInput +
+				tt.want.Output + tt.want.Reasoning + tt.want.Images + tt.want.Request
+			got := tt.model.EstimateCost(tt.usage)
+			fields := []struct {
+				name      string
+				got, want float64
+			}{
+				{"input", got.Input, tt.want.Input},
+				{"cache read", got.CacheRead, tt.want.CacheRead},
+				{"cache write", got.CacheWrite, tt.want.CacheWrite},
+				{"audio input", got.AudioInput, tt.want.AudioInput},
+				{"output", got.Output, tt.want.Output},
+				{"reasoning", got.Reasoning, tt.want.Reasoning},
+				{"images", got.Images, tt.want.Images},
+				{"request", got.Request, tt.want.Request},
+				{"total", got.Total, tt.want.Total},
+			}
+			for _, f := range fields {
+				if math.Abs(f.got-f.want) > 1e-12 {
+					t.Errorf("%s cost = %g, want %g", f.name, f.got, f.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package catwalk
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout is the layout of a [Date] in JSON.
+const dateLayout = time.DateOnly
+
+// Date is a calendar date, encoded in JSON as "YYYY-MM-DD".
+type Date struct {
+	time.Time
+}
+
+// DateOf returns the date of t, in UTC. The zero time yields the zero Date.
+func DateOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	y, m, d := t.UTC().Date()
+	return Date{time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+// String returns the date formatted as "YYYY-MM-DD".
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String()) //nolint:wrapcheck
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid date: %w", err)
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	*d = Date{t}
+	return nil
+}
@@ -0,0 +1,90 @@
+package catwalk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/catwalk/internal/retry"
+)
+
+// Errors reported by the [Client], to be checked with [errors.Is].
+var (
+	// ErrUnavailable reports that the service could not be reached, or
+	// that it is temporarily unable to serve requests (5xx and 429
+	// responses).
+	ErrUnavailable = errors.New("catwalk: service unavailable")
+	// ErrDecode reports that a response of the service could not be
+	// decoded.
+	ErrDecode = errors.New("catwalk: failed to decode response")
+)
+
+// maxErrorBody is the maximum number of bytes of a response body kept in an
+// [HTTPError].
+const maxErrorBody = 512
+
+// Error codes sent by the service in an [ErrorResponse].
+const (
+	ErrorCodeMethodNotAllowed = "method_not_allowed"
+	ErrorCodeInvalidQuery     = "invalid_query"
+	ErrorCodeInvalidRequest   = "invalid_request"
+	ErrorCodeProviderNotFound = "provider_not_found"
+	ErrorCodeModelNotFound    = "model_not_found"
+	ErrorCodeInternal         = "internal_error"
+)
+
+// ErrorResponse is the body of the error responses sent by the service.
+type ErrorResponse struct {
+	// Code is a machine readable error code, one of the ErrorCode
+	// constants.
+	Code string `json:"code"`
+	// Message is a human readable description of the error.
+	Message string `json:"message"`
+}
+
+// HTTPError is returned when the service answers with an unexpected status
+// code. It can be retrieved with [errors.As].
+type HTTPError struct {
+	StatusCode int
+	// Code and Message are set when the service sent an [ErrorResponse].
+	Code    string
+	Message string
+	// Body is an excerpt of the response body.
+	Body string
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	switch {
+	case e.Message != "":
+		msg += ": " + e.Message
+	case e.Body != "":
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is reports whether the error matches target. Server errors and 429 Too
+// Many Requests match [ErrUnavailable].
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrUnavailable && retry.Retryable(e.StatusCode)
+}
+
+// newHTTPError builds an [HTTPError] out of an unexpected response.
+func newHTTPError(resp *http.Response) *HTTPError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	e := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+	var er ErrorResponse
+	if json.Unmarshal(body, &er) == nil {
+		e.Code = er.Code
+		e.Message = er.Message
+	}
+	return e
+}
@@ -0,0 +1,116 @@
+package catwalk_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    response
+		unavailable bool
+		decode      bool
+		// code and message are those of the HTTPError, when one is
+		// expected.
+		code    string
+		message string
+	}{
+		{
+			name:        "server error",
+			response:    response{status: http.StatusInternalServerError, body: "boom"},
+			unavailable: true,
+			message:     "boom",
+		},
+		{
+			name:        "too many requests",
+			response:    response{status: http.StatusTooManyRequests},
+			unavailable: true,
+		},
+		{
+			name: "error response",
+			response: response{
+				status: http.StatusMethodNotAllowed,
+				body:   `{"code": "method_not_allowed", "message": "Method not allowed"}`,
+			},
+			code:    catwalk.ErrorCodeMethodNotAllowed,
+			message: "Method not allowed",
+		},
+		{
+			name:     "client error",
+			response: response{status: http.StatusNotFound, body: "not found"},
+			message:  "not found",
+		},
+		{
+			name:     "invalid body",
+			response: response{status: http.StatusOK, body: `[{"id": `},
+			decode:   true,
+		},
+		{
+			name:     "unexpected body",
+			response: response{status: http.StatusOK, body: `{"providers": []}`},
+			decode:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, tt.response)
+			_, err := catwalk.NewWithURL(srv.URL).GetProviders()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, catwalk.ErrUnavailable); got != tt.unavailable {
+				t.Errorf("errors.Is(%v, ErrUnavailable) = %v, want %v", err, got, tt.unavailable)
+			}
+			if got := errors.Is(err, catwalk.ErrDecode); got != tt.decode {
+				t.Errorf("errors.Is(%v, ErrDecode) = %v, want %v", err, got, tt.decode)
+			}
+
+			var httpErr *catwalk.HTTPError
+			if got := errors.As(err, &httpErr); got == tt.decode {
+				t.Fatalf("errors.As(%v, *HTTPError) = %v, want %v", err, got, !tt.decode)
+			}
+			if tt.decode {
+				return
+			}
+			if httpErr.StatusCode != tt.response.status {
+				t.Errorf("status = %d, want %d", httpErr.StatusCode, tt.response.status)
+			}
+			if httpErr.Code != tt.code {
+				t.Errorf("code = %q, want %q", httpErr.Code, tt.code)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not contain %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestErrorBodyExcerpt(t *testing.T) {
+	srv := newServer(t, response{status: http.StatusBadGateway, body: strings.Repeat("x", 10_000)})
+	_, err := catwalk.NewWithURL(srv.URL).GetProviders()
+	var httpErr *catwalk.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("expected an HTTPError, got: %v", err)
+	}
+	if len(httpErr.Body) != 512 {
+		t.Errorf("body excerpt is %d bytes long, want 512", len(httpErr.Body))
+	}
+}
+
+func TestErrorUnreachable(t *testing.T) {
+	srv := newServer(t)
+	srv.Close()
+	_, err := catwalk.NewWithURL(srv.URL).GetProviders()
+	if !errors.Is(err, catwalk.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable, got: %v", err)
+	}
+	var httpErr *catwalk.HTTPError
+	if errors.As(err, &httpErr) {
+		t.Errorf("unexpected HTTPError for a network error: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package catwalk
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/catwalk/internal/retry"
+)
+
+// Option configures a [Client].
+type Option func(*Client)
+
+// RetryPolicy controls how the client retries failed requests.
+//
+// Requests failing with a network error, a 5xx status or 429 Too Many
+// Requests are retried with exponential backoff: the n-th retry waits
+// BaseDelay * 2^(n-1), capped at MaxDelay. A Retry-After header sent by the
+// service takes precedence over the computed delay, within the same cap.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values lower than 1 are treated as 1.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. Zero means no cap.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is a sensible retry policy for interactive use.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Second,
+	MaxDelay:    10 * time.Second,
+}
+
+// delay returns how long to wait before the n-th retry, starting at 1.
+func (p RetryPolicy) delay(n int) time.Duration {
+	return p.clamp(retry.Backoff(p.BaseDelay, n))
+}
+
+func (p RetryPolicy) clamp(d time.Duration) time.Duration {
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// WithBaseURL sets the URL of the catwalk service.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = url
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to talk to the service.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout sets the timeout of a single call, retries included. Zero
+// disables the timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithBearerToken authenticates every request with the given bearer token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithRetry sets the retry policy of the client. By default requests are
+// not retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithFallback makes the client fall back to the last known good catalog
+// when the service is unreachable or fails, and to the catalog returned by
+// fn when there is none. It is typically used with the catalog shipped in
+// the embedded package:
+//
+//	client := catwalk.New(catwalk.WithFallback(embedded.GetAll))
+func WithFallback(fn func() []Provider) Option {
+	return func(c *Client) {
+		c.fallback = fn
+	}
+}
+
+// WithCache persists the last successful response of the service in dir,
+// or in [DefaultCacheDir] when dir is empty. A catalog younger than ttl is
+// served from the cache without contacting the service; an older one is
+// served while being revalidated in the background. A non-positive ttl
+// disables the cache.
+func WithCache(dir string, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cacheDir = dir
+		c.cacheTTL = ttl
+	}
+}
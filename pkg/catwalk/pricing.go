@@ -0,0 +1,98 @@
+package catwalk
+
+// TokenPrices holds per-token prices, in USD per million tokens.
+type TokenPrices struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+	// CacheRead is the price of input tokens read from the prompt cache.
+	CacheRead float64 `json:"cache_read,omitempty"`
+	// CacheWrite is the price of input tokens written to the prompt cache.
+	CacheWrite float64 `json:"cache_write,omitempty"`
+	// Reasoning is the price of reasoning tokens, when it differs from the
+	// price of output tokens.
+	Reasoning float64 `json:"reasoning,omitempty"`
+}
+
+// PricingTier holds the token prices applying once the prompt grows past a
+// given size, e.g. for long context requests.
+type PricingTier struct {
+	// AboveTokens is the prompt size, in tokens, past which the tier
+	// applies.
+	AboveTokens int64 `json:"above_tokens"`
+	// TokenPrices replace the base prices of the model. Zero prices are
+	// inherited from the base prices.
+	TokenPrices
+}
+
+// Pricing describes the price of a model.
+type Pricing struct {
+	// TokenPrices are the base prices of the model.
+	TokenPrices
+	// Tiers are the context length tiers of the model, sorted by
+	// increasing AboveTokens.
+	Tiers []PricingTier `json:"tiers,omitempty"`
+	// PerRequest is a fixed fee charged for every request, in USD.
+	PerRequest float64 `json:"per_request,omitempty"`
+	// PerImage is the price of every input image, in USD.
+	PerImage float64 `json:"per_image,omitempty"`
+	// AudioInput is the price of audio input tokens, in USD per million
+	// tokens.
+	AudioInput float64 `json:"audio_input,omitempty"`
+}
+
+// At returns the token prices applying to a prompt of the given size.
+func (p Pricing) At(promptTokens int64) TokenPrices {
+	prices := p.TokenPrices
+	for _, tier := range p.Tiers {
+		if promptTokens <= tier.AboveTokens {
+			break
+		}
+		prices = tier.TokenPrices.inherit(p.TokenPrices)
+	}
+	return prices
+}
+
+func (t TokenPrices) inherit(base TokenPrices) TokenPrices {
+	if t.Input == 0 {
+		t.Input = base.Input
+	}
+	if t.Output == 0 {
+		t.Output = base.Output
+	}
+	if t.CacheRead == 0 {
+		t.CacheRead = base.CacheRead
+	}
+	if t.CacheWrite == 0 {
+		t.CacheWrite = base.CacheWrite
+	}
+	if t.Reasoning == 0 {
+		t.Reasoning = base.Reasoning
+	}
+	return t
+}
+
+// Prices returns the pricing of the model. Models without a [Pricing] get
+// one built from their legacy cost fields.
+func (m Model) Prices() Pricing {
+	if m.Pricing != nil {
+		return *m.Pricing
+	}
+	return Pricing{
+		TokenPrices: TokenPrices{
+			Input:      m.CostPer1MIn,
+			Output:     m.CostPer1MOut,
+			CacheRead:  m.CostPer1MOutCached,
+			CacheWrite: m.CostPer1MInCached,
+		},
+	}
+}
+
+// SetPricing sets the pricing of the model, along with the legacy cost
+// fields read by older clients, which get the base prices.
+func (m *Model) SetPricing(p Pricing) {
+	m.Pricing = &p
+	m.CostPer1MIn = p.Input
+	m.CostPer1MOut = p.Output
+	m.CostPer1MInCached = p.CacheWrite
+	m.CostPer1MOutCached = p.CacheRead
+}
@@ -0,0 +1,70 @@
+package catwalk_test
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// tieredPricing is priced like a long context model, with a tier leaving
+// some prices unset.
+var tieredPricing = catwalk.Pricing{
+	TokenPrices: catwalk.TokenPrices{Input: 1.25, Output: 10, CacheRead: 0.31, CacheWrite: 1.5, Reasoning: 12},
+	Tiers: []catwalk.PricingTier{
+		{AboveTokens: 200_000, TokenPrices: catwalk.TokenPrices{Input: 2.5, Output: 15}},
+		{AboveTokens: 1_000_000, TokenPrices: catwalk.TokenPrices{Input: 5, Output: 20, CacheRead: 1}},
+	},
+}
+
+func TestPricingAt(t *testing.T) {
+	base := tieredPricing.TokenPrices
+	long := catwalk.TokenPrices{Input: 2.5, Output: 15, CacheRead: 0.31, CacheWrite: 1.5, Reasoning: 12}
+	longer := catwalk.TokenPrices{Input: 5, Output: 20, CacheRead: 1, CacheWrite: 1.5, Reasoning: 12}
+	tests := []struct {
+		name   string
+		tokens int64
+		want   catwalk.TokenPrices
+	}{
+		{name: "empty prompt", tokens: 0, want: base},
+		{name: "below the first tier", tokens: 199_999, want: base},
+		{name: "at the first tier", tokens: 200_000, want: base},
+		{name: "past the first tier", tokens: 200_001, want: long},
+		{name: "at the second tier", tokens: 1_000_000, want: long},
+		{name: "past the second tier", tokens: 1_000_001, want: longer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tieredPricing.At(tt.tokens); got != tt.want {
+				t.Errorf("At(%d) = %+v, want %+v", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPricingAtWithoutTiers(t *testing.T) {
+	p := catwalk.Pricing{TokenPrices: catwalk.TokenPrices{Input: 3, Output: 15}}
+	if got := p.At(10_000_000); got != p.TokenPrices {
+		t.Errorf("At = %+v, want the base prices %+v", got, p.TokenPrices)
+	}
+}
+
+func TestPrices(t *testing.T) {
+	// The legacy cost fields are named after the direction of the cache
+	// rather than the token kind: CostPer1MInCached is the price of writing
+	// input tokens to the cache, CostPer1MOutCached that of reading them.
+	legacy := catwalk.Model{CostPer1MIn: 3, CostPer1MOut: 15, CostPer1MInCached: 3.75, CostPer1MOutCached: 0.3}
+	want := catwalk.TokenPrices{Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75}
+	if got := legacy.Prices(); got.TokenPrices != want || got.Tiers != nil {
+		t.Errorf("Prices() = %+v, want %+v", got, want)
+	}
+
+	var m catwalk.Model
+	m.SetPricing(tieredPricing)
+	if m.CostPer1MIn != 1.25 || m.CostPer1MOut != 10 || m.CostPer1MInCached != 1.5 || m.CostPer1MOutCached != 0.31 {
+		t.Errorf("SetPricing set the legacy costs in %g, out %g, in cached %g, out cached %g, want the base prices",
+			m.CostPer1MIn, m.CostPer1MOut, m.CostPer1MInCached, m.CostPer1MOutCached)
+	}
+	if got := m.Prices(); len(got.Tiers) != len(tieredPricing.Tiers) {
+		t.Errorf("Prices() has %d tiers, want %d", len(got.Tiers), len(tieredPricing.Tiers))
+	}
+}
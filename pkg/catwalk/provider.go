@@ -1,5 +1,11 @@
 package catwalk
 
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
 // Type represents the type of AI provider.
 type Type string
 
@@ -16,24 +22,10 @@ const (
 // InferenceProvider represents the inference provider identifier.
 type InferenceProvider string
 
-// All the inference providers supported by the system.
-const (
-	InferenceProviderOpenAI      InferenceProvider = "openai"
-	InferenceProviderAnthropic   InferenceProvider = "anthropic"
-	InferenceProviderGemini      InferenceProvider = "gemini"
-	InferenceProviderAzure       InferenceProvider = "azure"
-	InferenceProviderBedrock     InferenceProvider = "bedrock"
-	InferenceProviderVertexAI    InferenceProvider = "vertexai"
-	InferenceProviderXAI         InferenceProvider = "xai"
-	InferenceProviderZAI         InferenceProvider = "zai"
-	InferenceProviderGROQ        InferenceProvider = "groq"
-	InferenceProviderOpenRouter  InferenceProvider = "openrouter"
-	InferenceProviderCerebras    InferenceProvider = "cerebras"
-	InferenceProviderVenice      InferenceProvider = "venice"
-	InferenceProviderChutes      InferenceProvider = "chutes"
-	InferenceProviderHuggingFace InferenceProvider = "huggingface"
-	InferenceAIHubMix            InferenceProvider = "aihubmix"
-)
+// InferenceAIHubMix is the AIHubMix inference provider.
+//
+// Deprecated: use [InferenceProviderAIHubMix].
+const InferenceAIHubMix = InferenceProviderAIHubMix
 
 // Provider represents an AI provider configuration.
 type Provider struct {
@@ -48,39 +40,97 @@ type Provider struct {
 	DefaultHeaders      map[string]string `json:"default_headers,omitempty"`
 }
 
+// Modality represents a kind of content a model can consume or produce.
+type Modality string
+
+// All the supported modalities.
+const (
+	ModalityText  Modality = "text"
+	ModalityImage Modality = "image"
+	ModalityAudio Modality = "audio"
+	ModalityVideo Modality = "video"
+	ModalityPDF   Modality = "pdf"
+)
+
 // Model represents an AI model configuration.
+//
+// The CostPer1M fields hold the base prices of the model, in USD per million
+// tokens, for older clients: CostPer1MInCached is the price of cache writes
+// and CostPer1MOutCached the price of cache reads. Newer clients should use
+// [Model.Prices], which also covers context tiers and other fees.
 type Model struct {
-	ID                     string  `json:"id"`
-	Name                   string  `json:"name"`
-	CostPer1MIn            float64 `json:"cost_per_1m_in"`
-	CostPer1MOut           float64 `json:"cost_per_1m_out"`
-	CostPer1MInCached      float64 `json:"cost_per_1m_in_cached"`
-	CostPer1MOutCached     float64 `json:"cost_per_1m_out_cached"`
-	ContextWindow          int64   `json:"context_window"`
-	DefaultMaxTokens       int64   `json:"default_max_tokens"`
-	CanReason              bool    `json:"can_reason"`
-	HasReasoningEffort     bool    `json:"has_reasoning_efforts"`
-	DefaultReasoningEffort string  `json:"default_reasoning_effort,omitempty"`
-	SupportsImages         bool    `json:"supports_attachments"`
+	ID                        string     `json:"id"`
+	Name                      string     `json:"name"`
+	CostPer1MIn               float64    `json:"cost_per_1m_in"`
+	CostPer1MOut              float64    `json:"cost_per_1m_out"`
+	CostPer1MInCached         float64    `json:"cost_per_1m_in_cached"`
+	CostPer1MOutCached        float64    `json:"cost_per_1m_out_cached"`
+	ContextWindow             int64      `json:"context_window"`
+	DefaultMaxTokens          int64      `json:"default_max_tokens"`
+	CanReason                 bool       `json:"can_reason"`
+	HasReasoningEffort        bool       `json:"has_reasoning_efforts"`
+	DefaultReasoningEffort    string     `json:"default_reasoning_effort,omitempty"`
+	SupportsImages            bool       `json:"supports_attachments"`
+	SupportsTools             bool       `json:"supports_tools,omitempty"`
+	SupportsParallelToolCalls bool       `json:"supports_parallel_tool_calls,omitempty"`
+	SupportsJSONMode          bool       `json:"supports_json_mode,omitempty"`
+	SupportsStructuredOutput  bool       `json:"supports_structured_output,omitempty"`
+	SupportsStreaming         bool       `json:"supports_streaming,omitempty"`
+	InputModalities           []Modality `json:"input_modalities,omitempty"`
+	OutputModalities          []Modality `json:"output_modalities,omitempty"`
+	ReleasedAt                Date       `json:"released_at,omitzero"`
+	KnowledgeCutoff           Date       `json:"knowledge_cutoff,omitzero"`
+	DeprecatedAt              Date       `json:"deprecated_at,omitzero"`
+	RetiresAt                 Date       `json:"retires_at,omitzero"`
+	ReplacementModelID        string     `json:"replacement_model_id,omitempty"`
+	Pricing                   *Pricing   `json:"pricing,omitempty"`
+	// Upstream is the provider serving the model, for the models of
+	// aggregators.
+	Upstream *Route `json:"upstream,omitempty"`
+	// Routes lists the upstream providers able to serve the model, best
+	// first, for aggregators letting requests pin one of them.
+	Routes []Route `json:"routes,omitempty"`
 }
 
-// KnownProviders returns all the known inference providers.
-func KnownProviders() []InferenceProvider {
-	return []InferenceProvider{
-		InferenceProviderOpenAI,
-		InferenceProviderAnthropic,
-		InferenceProviderGemini,
-		InferenceProviderAzure,
-		InferenceProviderBedrock,
-		InferenceProviderVertexAI,
-		InferenceProviderXAI,
-		InferenceProviderZAI,
-		InferenceProviderGROQ,
-		InferenceProviderOpenRouter,
-		InferenceProviderCerebras,
-		InferenceProviderVenice,
-		InferenceProviderChutes,
-		InferenceProviderHuggingFace,
-		InferenceAIHubMix,
+// SupportsInput reports whether the model accepts the given modality as
+// input.
+func (m Model) SupportsInput(modality Modality) bool {
+	return slices.Contains(m.InputModalities, modality)
+}
+
+// SupportsOutput reports whether the model can produce the given modality.
+func (m Model) SupportsOutput(modality Modality) bool {
+	return slices.Contains(m.OutputModalities, modality)
+}
+
+// Deprecated reports whether the model is deprecated at the given time.
+// Deprecated models still work, but are scheduled for retirement.
+func (m Model) Deprecated(now time.Time) bool {
+	return !m.DeprecatedAt.IsZero() && !now.Before(m.DeprecatedAt.Time)
+}
+
+// Retired reports whether the model is retired at the given time, meaning
+// the provider no longer serves it.
+func (m Model) Retired(now time.Time) bool {
+	return !m.RetiresAt.IsZero() && !now.Before(m.RetiresAt.Time)
+}
+
+// DeprecationNotice returns a message suitable to warn users that the model
+// is deprecated or retired at the given time, or an empty string.
+func (m Model) DeprecationNotice(now time.Time) string {
+	var msg string
+	switch {
+	case m.Retired(now):
+		msg = fmt.Sprintf("model %s was retired on %s", m.ID, m.RetiresAt)
+	case m.Deprecated(now) && !m.RetiresAt.IsZero():
+		msg = fmt.Sprintf("model %s is deprecated and will be retired on %s", m.ID, m.RetiresAt)
+	case m.Deprecated(now):
+		msg = fmt.Sprintf("model %s is deprecated", m.ID)
+	default:
+		return ""
+	}
+	if m.ReplacementModelID != "" {
+		msg += fmt.Sprintf("; use %s instead", m.ReplacementModelID)
 	}
+	return msg
 }
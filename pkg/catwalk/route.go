@@ -0,0 +1,34 @@
+package catwalk
+
+// Route describes the upstream provider serving a model behind an
+// aggregator, e.g. OpenRouter.
+type Route struct {
+	// Provider is the name of the upstream provider.
+	Provider string `json:"provider"`
+	// Tag identifies the endpoint of the upstream provider for the provider
+	// routing of the aggregator.
+	Tag string `json:"tag,omitempty"`
+	// Quantization is the quantization of the served model, e.g. fp8, when
+	// known.
+	Quantization string `json:"quantization,omitempty"`
+	// ModelID is the ID of the model to request from the aggregator, when
+	// it differs from the ID of the model, e.g. for a model pinned to an
+	// upstream provider.
+	ModelID string `json:"model_id,omitempty"`
+	// ContextWindow, MaxTokens and Pricing are the limits and prices of the
+	// upstream provider, when they are known.
+	ContextWindow int64    `json:"context_window,omitempty"`
+	MaxTokens     int64    `json:"max_tokens,omitempty"`
+	Pricing       *Pricing `json:"pricing,omitempty"`
+}
+
+// String returns the provider and quantization of the route.
+func (r *Route) String() string {
+	if r == nil {
+		return ""
+	}
+	if r.Quantization == "" {
+		return r.Provider
+	}
+	return r.Provider + " (" + r.Quantization + ")"
+}
@@ -0,0 +1,19 @@
+package catwalk
+
+// Source tells where a catalog returned by the [Client] came from.
+type Source string
+
+// All the sources a catalog can come from.
+const (
+	// SourceRemote is a catalog fetched from, or revalidated with, the
+	// catwalk service.
+	SourceRemote Source = "remote"
+	// SourceCache is a previously fetched catalog, served from memory or
+	// from the on-disk cache, either because it is still fresh or because
+	// the service could not be reached. It may be stale.
+	SourceCache Source = "cache"
+	// SourceFallback is the catalog set with [WithFallback], served because
+	// neither the service nor a cached catalog was available. It is as old
+	// as the program providing it.
+	SourceFallback Source = "fallback"
+)
@@ -1,13 +1,33 @@
 // Package embedded provides access to all providers in a embedded manner.
 // This basically means offline access to the providers.
+//
+// Extra provider configs can be loaded from the directory named by the
+// CATWALK_CONFIG_DIR environment variable. A provider found there replaces
+// the embedded provider with the same ID, and the other ones are added
+// after the embedded providers. The overlays found there, i.e. its
+// *.overlay.json files, are then applied to the providers.
 package embedded
 
 import (
+	"log"
+	"os"
+
 	"github.com/charmbracelet/catwalk/internal/providers"
 	"github.com/charmbracelet/catwalk/pkg/catwalk"
 )
 
-// GetAll returns all embedded providers.
+// GetAll returns all embedded providers, logging and leaving out the ones
+// whose config is invalid.
 func GetAll() []catwalk.Provider {
-	return providers.GetAll()
+	all, err := Load()
+	if err != nil {
+		log.Printf("Error loading provider configs: %v", err)
+	}
+	return all
+}
+
+// Load returns all embedded providers, along with the errors of the
+// providers whose config is invalid, which are left out.
+func Load() ([]catwalk.Provider, error) {
+	return providers.LoadWithDir(os.Getenv(providers.ConfigDirEnv)) //nolint:wrapcheck
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var reloads = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "catwalk",
+	Subsystem: "catalog",
+	Name:      "reloads_total",
+	Help:      "Total number of catalog reloads, by result",
+}, []string{"result"})
+
+// Reload results, as reported by the reloads metric.
+const (
+	reloadSuccess   = "success"
+	reloadUnchanged = "unchanged"
+	reloadFailure   = "failure"
+)
+
+// reload loads the providers and swaps the catalog for one built from
+// them. The current catalog is kept when loading fails, and when the
+// providers did not change, so that its validators stay the same.
+func (s *server) reload(load func() ([]catwalk.Provider, error)) {
+	all, err := load()
+	var c *catalog
+	if err == nil {
+		c, err = newCatalog(all)
+	}
+	if err != nil {
+		reloads.WithLabelValues(reloadFailure).Inc()
+		log.Printf("Failed to reload catalog, serving the previous one: %v", err)
+		return
+	}
+	if old := s.catalog.Load(); old != nil && old.etag == c.etag {
+		reloads.WithLabelValues(reloadUnchanged).Inc()
+		log.Printf("Reloaded catalog, unchanged (ETag %s)", c.etag)
+		return
+	}
+	s.catalog.Store(c)
+	reloads.WithLabelValues(reloadSuccess).Inc()
+	log.Printf("Reloaded catalog with %d providers (ETag %s)", len(all), c.etag)
+}
+
+// watch polls the config directory and the overlay files every interval,
+// and reloads the catalog whenever they change.
+func (s *server) watch(dir string, overlays []string, interval time.Duration, load func() ([]catwalk.Provider, error)) {
+	last := snapshot(dir, overlays)
+	for range time.Tick(interval) {
+		current := snapshot(dir, overlays)
+		if current == last {
+			continue
+		}
+		last = current
+		s.reload(load)
+	}
+}
+
+// snapshot returns a fingerprint of the names, sizes and modification
+// times of the files the catalog is loaded from.
+func snapshot(dir string, overlays []string) string {
+	var files []string
+	if dir != "" {
+		files, _ = filepath.Glob(filepath.Join(dir, "*.json"))
+	}
+	files = append(files, overlays...)
+	slices.Sort(files)
+
+	var sb strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s:missing\n", file)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String()
+}
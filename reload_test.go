@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/catwalk/internal/providers"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const acmeConfig = `{
+  "id": "acme",
+  "name": %q,
+  "type": "openai",
+  "default_large_model_id": "acme-1",
+  "default_small_model_id": "acme-1",
+  "models": [{"id": "acme-1", "name": "Acme 1", "context_window": 8192, "default_max_tokens": 1024}]
+}`
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "acme.json")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func() ([]catwalk.Provider, error) {
+		return providers.LoadWithDir(dir) //nolint:wrapcheck
+	}
+	acmeName := func(c *catalog) string {
+		t.Helper()
+		p, ok := c.provider("acme")
+		if !ok {
+			t.Fatal("provider acme not found")
+		}
+		return p.Name
+	}
+
+	write(fmt.Sprintf(acmeConfig, "Acme"))
+	all, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newCatalog(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{}
+	s.catalog.Store(c)
+
+	tests := []struct {
+		name string
+		// data is written to the config file before reloading.
+		data   string
+		result string
+		// changed reports whether the catalog is swapped.
+		changed bool
+		want    string
+	}{
+		{name: "unchanged", data: fmt.Sprintf(acmeConfig, "Acme"), result: reloadUnchanged, want: "Acme"},
+		{name: "changed", data: fmt.Sprintf(acmeConfig, "Acme Corp"), result: reloadSuccess, changed: true, want: "Acme Corp"},
+		{name: "invalid json", data: "{", result: reloadFailure, want: "Acme Corp"},
+		{name: "invalid config", data: fmt.Sprintf(acmeConfig, ""), result: reloadFailure, want: "Acme Corp"},
+		{name: "fixed", data: fmt.Sprintf(acmeConfig, "Acme"), result: reloadSuccess, changed: true, want: "Acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := map[string]float64{}
+			for _, result := range []string{reloadSuccess, reloadUnchanged, reloadFailure} {
+				counts[result] = testutil.ToFloat64(reloads.WithLabelValues(result))
+			}
+			old := s.catalog.Load()
+
+			write(tt.data)
+			s.reload(load)
+
+			for result, count := range counts {
+				want := count
+				if result == tt.result {
+					want++
+				}
+				if got := testutil.ToFloat64(reloads.WithLabelValues(result)); got != want {
+					t.Errorf("reloads_total{result=%q} = %g, want %g", result, got, want)
+				}
+			}
+			current := s.catalog.Load()
+			if changed := current != old; changed != tt.changed {
+				t.Errorf("catalog swapped = %v, want %v", changed, tt.changed)
+			}
+			if changed := current.etag != old.etag; changed != tt.changed {
+				t.Errorf("ETag changed = %v, want %v", changed, tt.changed)
+			}
+			if got := acmeName(current); got != tt.want {
+				t.Errorf("provider name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	overlay := filepath.Join(t.TempDir(), "overlay.json")
+	first := snapshot(dir, []string{overlay})
+
+	if err := os.WriteFile(overlay, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	second := snapshot(dir, []string{overlay})
+	if second == first {
+		t.Error("creating an overlay did not change the snapshot")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "acme.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	third := snapshot(dir, []string{overlay})
+	if third == second {
+		t.Error("adding a config did not change the snapshot")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot(dir, []string{overlay}) != third {
+		t.Error("a file other than a config changed the snapshot")
+	}
+}
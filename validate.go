@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/catwalk/internal/providers"
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+)
+
+// defaultConfigGlob matches the provider configs shipped with catwalk,
+// relative to the root of the repository.
+const defaultConfigGlob = "internal/providers/configs/*.json"
+
+// runValidate implements the validate command, which checks the given
+// provider config files, or the ones shipped with catwalk when none are
+// given.
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	custom := fs.Bool("custom", false, "validate custom provider configs, which may use any provider ID")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: catwalk validate [-custom] [file...]\n\n")
+		fmt.Fprintf(fs.Output(), "Validates provider config files, by default %s.\n\n", defaultConfigGlob)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	validate := providers.Validate
+	if *custom {
+		validate = providers.ValidateCustom
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		var err error
+		files, err = filepath.Glob(defaultConfigGlob)
+		if err != nil {
+			return fmt.Errorf("failed to list configs: %w", err)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no configs found matching %s", defaultConfigGlob)
+		}
+	}
+
+	var failed int
+	for _, file := range files {
+		if err := validateFile(file, validate); err != nil {
+			failed++
+			fmt.Printf("%s:\n", file)
+			for _, e := range unwrapJoined(err) {
+				fmt.Printf("  - %v\n", e)
+			}
+			continue
+		}
+		fmt.Printf("%s: ok\n", file)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d configs are invalid", failed, len(files))
+	}
+	return nil
+}
+
+func validateFile(path string, validate func(catwalk.Provider) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	p, err := providers.DecodeConfig(data)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	return validate(p)
+}
+
+// unwrapJoined returns the errors joined in err, or err itself.
+func unwrapJoined(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}